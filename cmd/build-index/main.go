@@ -0,0 +1,123 @@
+// Command build-index builds the jibun and road-name address tries offline,
+// validates them and writes a snapshot the server can load through
+// SNAPSHOT_PATH. Both tries are written to the one snapshot file under a
+// single checksum.
+//
+//	go run ./cmd/build-index -source s3 -out trie.snapshot
+//	go run ./cmd/build-index -source files -path ./addresses.zip -out trie.snapshot
+//	go run ./cmd/build-index -source postgres -out trie.snapshot
+//	zcat addresses.txt.gz | go run ./cmd/build-index -source stdin -out trie.snapshot
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gin-project/database"
+	"gin-project/service"
+	"gin-project/trie"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	// .env 파일 로드
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	kind := flag.String("source", database.SourceS3, "address source: s3, postgres, files or stdin")
+	path := flag.String("path", "", "directory, .txt or .zip file for -source files")
+	out := flag.String("out", "trie.snapshot", "snapshot file to write")
+	batchSize := flag.Int("batch", getBatchSize("BATCH_SIZE", database.DefaultBatchSize), "addresses per batch")
+	verify := flag.Bool("verify", true, "read the snapshot back and compare it with the built trie")
+	flag.Parse()
+
+	source, err := database.SourceFromEnv(*kind, *path)
+	if err != nil {
+		log.Fatalf("Failed to configure address source: %v", err)
+	}
+
+	start := time.Now()
+	index, err := service.Build(context.Background(), source, *batchSize)
+	if err != nil {
+		log.Fatalf("Failed to build trie: %v", err)
+	}
+	log.Printf("Built trie in %s", time.Since(start).Round(time.Millisecond))
+
+	// Trie 상태 출력
+	service.PrintTrieStatus(index)
+
+	jibunStats := checkTrie("Jibun", index.Jibun)
+	roadStats := checkTrie("Road-name", index.Road)
+
+	if err := index.SaveSnapshot(*out); err != nil {
+		log.Fatalf("Failed to write snapshot: %v", err)
+	}
+
+	if info, err := os.Stat(*out); err == nil {
+		log.Printf("Wrote snapshot %s (%d bytes)", *out, info.Size())
+	}
+
+	if *verify {
+		if err := verifySnapshot(*out, jibunStats, roadStats); err != nil {
+			log.Fatalf("Snapshot verification failed: %v", err)
+		}
+		log.Println("Snapshot verified")
+	}
+}
+
+// checkTrie logs the size of a built trie, exits when it is invalid and returns its stats
+func checkTrie(name string, nodes *trie.NodeManager) trie.Stats {
+	stats := nodes.Stats()
+	log.Printf("%s trie - nodes: %d, words: %d, payloads: %d, jump references: %v", name, stats.Nodes, stats.Words, stats.Payloads, stats.Refs)
+
+	if err := nodes.Validate(); err != nil {
+		log.Fatalf("%s trie is invalid: %v", name, err)
+	}
+	log.Printf("%s trie is valid", name)
+	return stats
+}
+
+// verifySnapshot loads the written snapshot and checks both tries have the same shape as the built ones
+func verifySnapshot(path string, jibun trie.Stats, road trie.Stats) error {
+	index, err := service.LoadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	if err := compareStats(index.Jibun, jibun); err != nil {
+		return fmt.Errorf("jibun trie: %w", err)
+	}
+	if err := compareStats(index.Road, road); err != nil {
+		return fmt.Errorf("road-name trie: %w", err)
+	}
+	return nil
+}
+
+func compareStats(nodes *trie.NodeManager, expected trie.Stats) error {
+	if err := nodes.Validate(); err != nil {
+		return err
+	}
+
+	actual := nodes.Stats()
+	if actual.Nodes != expected.Nodes || actual.Words != expected.Words ||
+		actual.Payloads != expected.Payloads || actual.Tokens != expected.Tokens ||
+		!slices.Equal(actual.Refs, expected.Refs) {
+		return fmt.Errorf("snapshot stats %+v differ from built trie %+v", actual, expected)
+	}
+	return nil
+}
+
+func getBatchSize(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultValue
+}
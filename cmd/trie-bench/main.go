@@ -0,0 +1,126 @@
+// Command trie-bench compares memory use and search latency of NodeManager and
+// the experimental RadixTree on a generated land address corpus. RadixTree is
+// not used by the service; the comparison covers exact and 초성 matching only,
+// since RadixTree lacks NodeManager's jamo matching of an unfinished last
+// syllable and its lot number tiers, so result counts can differ.
+//
+//	go run ./cmd/trie-bench -addresses 500000 -queries 20000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gin-project/trie"
+	"log"
+	"math/rand"
+	"runtime"
+	"strings"
+	"time"
+)
+
+var sidos = []string{
+	"서울특별시", "부산광역시", "대구광역시", "인천광역시", "광주광역시", "대전광역시",
+	"울산광역시", "세종특별자치시", "경기도", "강원특별자치도", "충청북도", "충청남도",
+	"전북특별자치도", "전라남도", "경상북도", "경상남도", "제주특별자치도",
+}
+
+var syllables = []rune("가강경고관광구군금김남노대덕동마명문미반방백보부북사산삼상서석성송수시신안양여역연영오옥용우원월유은의이인장정제조중지진창천청초춘충태평포하학한해행호화효흥")
+
+// index is the part of NodeManager and RadixTree being compared
+type index interface {
+	Insert(address string, score float32, payload *trie.Payload)
+	Search(query string, limit int) []trie.Result
+}
+
+func main() {
+	addressCount := flag.Int("addresses", 200000, "number of generated addresses")
+	queryCount := flag.Int("queries", 10000, "number of searches to time")
+	limit := flag.Int("limit", 10, "results per search")
+	seed := flag.Int64("seed", 1, "random seed for the corpus")
+	flag.Parse()
+
+	random := rand.New(rand.NewSource(*seed))
+	addresses := generateAddresses(random, *addressCount)
+	queries := generateQueries(random, addresses, *queryCount)
+	log.Printf("Generated %d addresses and %d queries", len(addresses), len(queries))
+
+	fmt.Printf("%-12s %12s %12s %14s %14s\n", "structure", "heap (MiB)", "build", "search avg", "results")
+	run("NodeManager", func() index { return trie.CreateNodes() }, addresses, queries, *limit)
+	run("RadixTree", func() index { return trie.CreateRadixTree() }, addresses, queries, *limit)
+}
+
+func run(name string, create func() index, addresses, queries []string, limit int) {
+	before := heapInUse()
+
+	start := time.Now()
+	idx := create()
+	for _, address := range addresses {
+		idx.Insert(address, 0, nil)
+	}
+	build := time.Since(start)
+
+	heap := heapInUse() - before
+
+	found := 0
+	start = time.Now()
+	for _, query := range queries {
+		found += len(idx.Search(query, limit))
+	}
+	search := time.Since(start) / time.Duration(max(len(queries), 1))
+
+	fmt.Printf("%-12s %12.1f %12s %14s %14d\n", name, float64(heap)/(1<<20), build.Round(time.Millisecond), search, found)
+	runtime.KeepAlive(idx)
+}
+
+func heapInUse() uint64 {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+// generateAddresses builds addresses shaped like "시도 시군구 읍면동 [산 ]번지[-호]"
+func generateAddresses(random *rand.Rand, count int) []string {
+	addresses := make([]string, 0, count)
+	suffixes := []string{"구", "시", "군"}
+
+	for len(addresses) < count {
+		sido := sidos[random.Intn(len(sidos))]
+		sigungu := randomName(random, 2) + suffixes[random.Intn(len(suffixes))]
+		dong := randomName(random, 2) + "동"
+
+		// 같은 동에 여러 필지가 몰려 있도록 한 번에 여러 개 생성
+		for lots := 1 + random.Intn(200); lots > 0 && len(addresses) < count; lots-- {
+			lot := fmt.Sprintf("%d", 1+random.Intn(999))
+			if random.Intn(3) == 0 {
+				lot += fmt.Sprintf("-%d", 1+random.Intn(30))
+			}
+			if random.Intn(20) == 0 {
+				lot = "산 " + lot
+			}
+			addresses = append(addresses, strings.Join([]string{sido, sigungu, dong, lot}, " "))
+		}
+	}
+
+	return addresses
+}
+
+// generateQueries takes prefixes of addresses and of their words after the sido
+func generateQueries(random *rand.Rand, addresses []string, count int) []string {
+	queries := make([]string, 0, count)
+	for len(queries) < count {
+		words := strings.Split(addresses[random.Intn(len(addresses))], " ")
+		from := random.Intn(len(words) - 1)
+		runes := []rune(strings.Join(words[from:], " "))
+		queries = append(queries, string(runes[:1+random.Intn(min(len(runes), 8))]))
+	}
+	return queries
+}
+
+func randomName(random *rand.Rand, length int) string {
+	name := make([]rune, length)
+	for i := range name {
+		name[i] = syllables[random.Intn(len(syllables))]
+	}
+	return string(name)
+}
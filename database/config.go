@@ -0,0 +1,114 @@
+package database
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"gin-project/secrets"
+)
+
+// ConfigFromEnv reads the connection settings from the environment, taking the
+// credentials from AWS Secrets Manager when DB_SECRET_NAME is set
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		Host:     getEnv("DB_HOST", "localhost"),
+		Port:     getPort("DB_PORT", 5432),
+		User:     getEnv("DB_USER", "postgres"),
+		Password: getEnv("DB_PASSWORD", ""),
+		DBName:   getEnv("DB_NAME", "postgres"),
+		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	}
+
+	if secretName := getEnv("DB_SECRET_NAME", ""); secretName != "" {
+		sm := secrets.NewSecretsManager(getEnv("AWS_REGION", "ap-northeast-2"))
+		user, password, err := sm.GetDatabaseCredentials(secretName)
+		if err != nil {
+			return Config{}, err
+		}
+		config.User = user
+		config.Password = password
+	}
+
+	return config, nil
+}
+
+// S3ConfigFromEnv reads the S3 location from the environment. S3_KEYS and
+// S3_PREFIXES are comma separated; the default key is used when both are empty.
+func S3ConfigFromEnv() S3Config {
+	config := DefaultS3Config()
+	config.Bucket = getEnv("S3_BUCKET", config.Bucket)
+	config.Region = getEnv("S3_REGION", getEnv("AWS_REGION", config.Region))
+	config.Endpoint = getEnv("S3_ENDPOINT", "")
+	config.ForcePathStyle = getBool("S3_FORCE_PATH_STYLE", false)
+	config.MaxBufferSize = int64(getSize("S3_MAX_BUFFER_SIZE", int(config.MaxBufferSize)))
+	config.CacheDir = getEnv("S3_CACHE_DIR", "")
+
+	keys := getList("S3_KEYS")
+	prefixes := getList("S3_PREFIXES")
+	if len(keys) > 0 || len(prefixes) > 0 {
+		config.Keys = keys
+		config.Prefixes = prefixes
+	}
+
+	return config
+}
+
+// SourceFromEnv returns the source of the given kind, reading the S3 or
+// database settings from the environment
+func SourceFromEnv(kind, path string) (AddressSource, error) {
+	config := SourceConfig{Kind: kind, Path: path, S3: S3ConfigFromEnv()}
+	if kind == SourcePostgres {
+		dbConfig, err := ConfigFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		config.DB = dbConfig
+	}
+	return NewAddressSource(config)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getPort(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if port, err := strconv.Atoi(value); err == nil && port > 0 {
+			return port
+		}
+	}
+	return defaultValue
+}
+
+func getSize(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if size, err := strconv.Atoi(value); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultValue
+}
+
+func getBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getList splits a comma separated variable, dropping empty items
+func getList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -17,11 +17,14 @@ type Config struct {
 	SSLMode  string
 }
 
-func Connect(config Config) (*sql.DB, error) {
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+// connString returns the lib/pq connection string for config
+func (config Config) connString() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode)
+}
 
-	db, err := sql.Open("postgres", connStr)
+func Connect(config Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.connString())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// LoadLandAddressesFromFilesBatch reads addresses from a local directory of
+// .txt files, a single .txt file or a ZIP archive of .txt files.
+func LoadLandAddressesFromFilesBatch(ctx context.Context, path string, batchSize int, processor func([]LandAddress) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	processor = withContext(ctx, processor)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	if info.IsDir() {
+		return processTextFiles(path, batchSize, processor)
+	}
+
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		return processZipFile(path, batchSize, processor)
+	}
+
+	totalProcessed := 0
+	batch := make([]LandAddress, 0, batchSize)
+	if err := processTextFile(path, batchSize, &batch, processor, &totalProcessed); err != nil {
+		return err
+	}
+	return flushBatch(batch, processor, totalProcessed)
+}
+
+// LoadLandAddressesFromReaderBatch reads address lines from r, such as stdin
+func LoadLandAddressesFromReaderBatch(ctx context.Context, r io.Reader, name string, batchSize int, processor func([]LandAddress) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	processor = withContext(ctx, processor)
+
+	totalProcessed := 0
+	batch := make([]LandAddress, 0, batchSize)
+	if err := scanTextFile(r, name, batchSize, &batch, processor, &totalProcessed); err != nil {
+		return err
+	}
+	return flushBatch(batch, processor, totalProcessed)
+}
+
+// processZipFile reads every .txt entry of a ZIP archive without extracting it
+func processZipFile(path string, batchSize int, processor func([]LandAddress) error) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+	defer r.Close()
+
+	totalProcessed := 0
+	batch := make([]LandAddress, 0, batchSize)
+	if err := processZipReader(&r.Reader, batchSize, &batch, processor, &totalProcessed); err != nil {
+		return err
+	}
+	return flushBatch(batch, processor, totalProcessed)
+}
+
+// processZipReader scans every .txt entry of an opened ZIP archive, handing
+// full batches to processor
+func processZipReader(r *zip.Reader, batchSize int, batch *[]LandAddress, processor func([]LandAddress) error, totalProcessed *int) error {
+	for _, f := range r.File {
+		if !isTextEntry(f) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open file %s in ZIP: %w", f.Name, err)
+		}
+
+		err = scanTextFile(rc, f.Name, batchSize, batch, processor, totalProcessed)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to process file %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// isTextEntry reports whether a ZIP entry is an address file, skipping
+// directories, __MACOSX metadata and hidden files
+func isTextEntry(f *zip.File) bool {
+	if f.FileInfo().IsDir() || strings.Contains(f.Name, "__MACOSX") {
+		return false
+	}
+	name := f.FileInfo().Name()
+	return !strings.HasPrefix(name, ".") && strings.HasSuffix(strings.ToLower(name), ".txt")
+}
+
+// withContext stops the load at the next batch once ctx is cancelled
+func withContext(ctx context.Context, processor func([]LandAddress) error) func([]LandAddress) error {
+	return func(batch []LandAddress) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return processor(batch)
+	}
+}
+
+// flushBatch processes the last partial batch and logs the total
+func flushBatch(batch []LandAddress, processor func([]LandAddress) error, totalProcessed int) error {
+	if len(batch) > 0 {
+		if err := processor(batch); err != nil {
+			return fmt.Errorf("failed to process final batch: %w", err)
+		}
+		totalProcessed += len(batch)
+		log.Printf("Processed final batch: %d addresses", len(batch))
+	}
+
+	log.Printf("Total addresses processed: %d", totalProcessed)
+	return nil
+}
@@ -1,43 +1,82 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 )
 
 const DefaultBatchSize = 1000
 
-func LoadLandAddressesBatch(db *sql.DB, batchSize int, processor func([]string) error) error {
+// LandAddress is a single address record read from a source
+type LandAddress struct {
+	Address string
+	// Weight ranks the address among suggestions; higher is more popular
+	Weight float32
+	// UniqueNo, FullCode and the center point (Lat, Lng) identify the parcel
+	// and are empty when the source does not provide them
+	UniqueNo string
+	FullCode string
+	Lat      float64
+	Lng      float64
+	// RoadAddress is the road-name address of the same parcel, empty when unknown
+	RoadAddress string
+}
+
+// LoadLandAddressesBatch reads the land table in batches using keyset
+// pagination on (full_code, id), so each batch is an index range scan no
+// matter how deep into the table it is and no row is skipped or repeated.
+func LoadLandAddressesBatch(ctx context.Context, db *sql.DB, batchSize int, processor func([]LandAddress) error) error {
 	if batchSize <= 0 {
 		batchSize = DefaultBatchSize
 	}
+	processor = withContext(ctx, processor)
+
+	// full_code는 NULL일 수 있으므로 빈 문자열로 정렬 (land_full_code_id_idx 사용)
+	query := `SELECT id, address, unique_no, COALESCE(full_code, ''), ST_Y(center_point), ST_X(center_point),
+			COALESCE(road_address, '')
+		FROM land
+		WHERE address IS NOT NULL AND address != '' AND (COALESCE(full_code, ''), id) > ($2, $3)
+		ORDER BY COALESCE(full_code, ''), id
+		LIMIT $1`
 
 	totalProcessed := 0
-	offset := 0
+	lastFullCode := ""
+	var lastID int64
 
 	for {
-		// DB에서 배치 단위로 데이터 가져오기
-		query := "SELECT address FROM land WHERE address IS NOT NULL AND address != '' ORDER BY full_code LIMIT $1 OFFSET $2"
-
-		rows, err := db.Query(query, batchSize, offset)
+		// DB에서 마지막으로 읽은 키 다음부터 배치 단위로 데이터 가져오기
+		rows, err := db.QueryContext(ctx, query, batchSize, lastFullCode, lastID)
 		if err != nil {
-			return fmt.Errorf("failed to query land addresses at offset %d: %w", offset, err)
+			return fmt.Errorf("failed to query land addresses after (%q, %d): %w", lastFullCode, lastID, err)
 		}
 
-		batch := make([]string, 0, batchSize)
+		batch := make([]LandAddress, 0, batchSize)
 
 		// 현재 배치의 데이터 읽기
 		for rows.Next() {
-			var address string
-			if err := rows.Scan(&address); err != nil {
+			var record LandAddress
+			var lat, lng sql.NullFloat64
+			if err := rows.Scan(&lastID, &record.Address, &record.UniqueNo, &record.FullCode, &lat, &lng, &record.RoadAddress); err != nil {
 				rows.Close()
 				return fmt.Errorf("failed to scan address: %w", err)
 			}
-			batch = append(batch, address)
+			lastFullCode = record.FullCode
+			record.Lat = lat.Float64
+			record.Lng = lng.Float64
+
+			// land 테이블에는 인기도 컬럼이 없으므로 가중치는 0
+			batch = append(batch, record)
 		}
 
+		err = rows.Err()
 		rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read land addresses: %w", err)
+		}
 
 		// 더 이상 데이터가 없으면 종료
 		if len(batch) == 0 {
@@ -46,14 +85,11 @@ func LoadLandAddressesBatch(db *sql.DB, batchSize int, processor func([]string)
 
 		// 배치 처리
 		if err := processor(batch); err != nil {
-			return fmt.Errorf("failed to process batch at offset %d: %w", offset, err)
+			return fmt.Errorf("failed to process batch ending at (%q, %d): %w", lastFullCode, lastID, err)
 		}
 
 		totalProcessed += len(batch)
-		log.Printf("Processed batch: %d addresses (offset: %d, total: %d)", len(batch), offset, totalProcessed)
-
-		// 다음 배치로 이동
-		offset += batchSize
+		log.Printf("Processed batch: %d addresses (last id: %d, total: %d)", len(batch), lastID, totalProcessed)
 
 		// 배치 크기보다 적게 가져왔다면 마지막 배치
 		if len(batch) < batchSize {
@@ -64,3 +100,133 @@ func LoadLandAddressesBatch(db *sql.DB, batchSize int, processor func([]string)
 	log.Printf("Total addresses processed: %d", totalProcessed)
 	return nil
 }
+
+// Watermark is the position of the last change read from the land table
+type Watermark struct {
+	UpdatedAt time.Time
+	ID        int64
+}
+
+// LandChange is a land row that changed after a watermark. Deleted is set when
+// the row was deleted or no longer has an address; a cleared RoadAddress only
+// removes the road-name address.
+type LandChange struct {
+	LandAddress
+	Deleted bool
+}
+
+// CheckLandTombstones returns an error unless the land_tombstone table, where
+// the land_tombstone trigger records deleted land rows, exists
+func CheckLandTombstones(ctx context.Context, db *sql.DB) error {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('land_tombstone') IS NOT NULL`).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check land_tombstone table: %w", err)
+	}
+	if !exists {
+		return errors.New("land_tombstone table not found, apply land_tombstone.sql so deleted land rows can be synced")
+	}
+	return nil
+}
+
+// CurrentWatermark returns the position of the newest change in the land
+// table, deletions included
+func CurrentWatermark(ctx context.Context, db *sql.DB) (Watermark, error) {
+	query := `SELECT updated_at, id
+		FROM (SELECT updated_at, id FROM land WHERE updated_at IS NOT NULL
+			UNION ALL
+			SELECT deleted_at, id FROM land_tombstone) changes
+		ORDER BY updated_at DESC, id DESC
+		LIMIT 1`
+
+	var watermark Watermark
+	err := db.QueryRowContext(ctx, query).Scan(&watermark.UpdatedAt, &watermark.ID)
+	if err == sql.ErrNoRows {
+		return Watermark{}, nil
+	}
+	if err != nil {
+		return Watermark{}, fmt.Errorf("failed to query land watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+// LoadLandChanges reads the rows changed after since in (updated_at, id) order,
+// handing them to processor in batches, and returns the watermark of the last
+// row read (since itself when nothing changed). Rows deleted from the table
+// are read from land_tombstone as deletions.
+func LoadLandChanges(ctx context.Context, db *sql.DB, since Watermark, batchSize int, processor func([]LandChange) error) (Watermark, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	// land_updated_at_id_idx, land_tombstone_deleted_at_id_idx 사용
+	// 지워진 행은 주소가 빈 변경으로 읽어 삭제로 처리
+	query := `SELECT id, updated_at, COALESCE(address, ''), unique_no, COALESCE(full_code, ''), ST_Y(center_point), ST_X(center_point),
+			COALESCE(road_address, '')
+		FROM land
+		WHERE (updated_at, id) > ($2, $3)
+		UNION ALL
+		SELECT id, deleted_at, '', unique_no, '', NULL, NULL, ''
+		FROM land_tombstone
+		WHERE (deleted_at, id) > ($2, $3)
+		ORDER BY updated_at, id
+		LIMIT $1`
+
+	watermark := since
+	totalProcessed := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return watermark, err
+		}
+
+		rows, err := db.QueryContext(ctx, query, batchSize, watermark.UpdatedAt, watermark.ID)
+		if err != nil {
+			return watermark, fmt.Errorf("failed to query land changes after %s: %w", watermark.UpdatedAt.Format(time.RFC3339Nano), err)
+		}
+
+		batch := make([]LandChange, 0, batchSize)
+		next := watermark
+
+		for rows.Next() {
+			var change LandChange
+			var lat, lng sql.NullFloat64
+			if err := rows.Scan(&next.ID, &next.UpdatedAt, &change.Address, &change.UniqueNo, &change.FullCode, &lat, &lng, &change.RoadAddress); err != nil {
+				rows.Close()
+				return watermark, fmt.Errorf("failed to scan land change: %w", err)
+			}
+			change.Lat = lat.Float64
+			change.Lng = lng.Float64
+
+			// 주소가 지워진 필지는 삭제로 처리
+			change.Deleted = change.Address == ""
+			batch = append(batch, change)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return watermark, fmt.Errorf("failed to read land changes: %w", err)
+		}
+
+		if len(batch) == 0 {
+			break
+		}
+
+		if err := processor(batch); err != nil {
+			return watermark, fmt.Errorf("failed to process land changes: %w", err)
+		}
+
+		// 배치를 모두 적용한 뒤에만 워터마크 이동
+		watermark = next
+		totalProcessed += len(batch)
+
+		if len(batch) < batchSize {
+			break
+		}
+	}
+
+	if totalProcessed > 0 {
+		log.Printf("Processed %d land changes (watermark: %s)", totalProcessed, watermark.UpdatedAt.Format(time.RFC3339Nano))
+	}
+	return watermark, nil
+}
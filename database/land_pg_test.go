@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+// openTestDB connects to TEST_DATABASE_URL and creates temporary land and
+// land_tombstone tables shadowing any real ones for the test connection
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	// 임시 테이블은 연결마다 따로이므로 연결을 하나만 사용
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	var postgis bool
+	if err := db.QueryRow(`SELECT to_regtype('geometry') IS NOT NULL`).Scan(&postgis); err != nil {
+		t.Fatalf("failed to check PostGIS: %v", err)
+	}
+	if !postgis {
+		t.Skip("PostGIS is not installed in the test database")
+	}
+
+	execSQL(t, db, `CREATE TEMP TABLE land (
+		id           bigint PRIMARY KEY,
+		unique_no    varchar(20) NOT NULL UNIQUE,
+		full_code    varchar(10),
+		address      varchar(128),
+		road_address varchar(128),
+		center_point geometry(Point, 4326),
+		updated_at   timestamp DEFAULT current_timestamp
+	)`)
+	execSQL(t, db, `CREATE TEMP TABLE land_tombstone (
+		id         bigint PRIMARY KEY,
+		unique_no  varchar(20) NOT NULL,
+		deleted_at timestamp NOT NULL DEFAULT current_timestamp
+	)`)
+	return db
+}
+
+func execSQL(t *testing.T, db *sql.DB, query string, args ...any) {
+	t.Helper()
+
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("failed to execute %q: %v", query, err)
+	}
+}
+
+// insertLandFixture fills land with NULL, empty and duplicate full_code rows
+// and rows without an address, which must be skipped
+func insertLandFixture(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	execSQL(t, db, `INSERT INTO land (id, unique_no, full_code, address, road_address, center_point) VALUES
+		(1, 'U1', '1168010100', '서울특별시 강남구 역삼동 1', '서울특별시 강남구 테헤란로 1', ST_SetSRID(ST_MakePoint(127.0, 37.5), 4326)),
+		(2, 'U2', NULL, '세종특별자치시 반곡동 1', NULL, ST_SetSRID(ST_MakePoint(127.3, 36.5), 4326)),
+		(3, 'U3', '1168010100', '서울특별시 강남구 역삼동 2', NULL, ST_SetSRID(ST_MakePoint(127.0, 37.5), 4326)),
+		(4, 'U4', NULL, '세종특별자치시 반곡동 2', NULL, NULL),
+		(5, 'U5', '1168010100', NULL, NULL, NULL),
+		(6, 'U6', '1168010100', '', NULL, NULL),
+		(7, 'U7', '1168010100', '서울특별시 강남구 역삼동 3', NULL, ST_SetSRID(ST_MakePoint(127.0, 37.5), 4326)),
+		(8, 'U8', '1168010300', '서울특별시 강남구 개포동 1', '서울특별시 강남구 개포로 1', ST_SetSRID(ST_MakePoint(127.1, 37.4), 4326)),
+		(9, 'U9', '', '서울특별시 강남구 개포동 2', NULL, NULL)`)
+}
+
+func TestLoadLandAddressesBatchPostgres(t *testing.T) {
+	db := openTestDB(t)
+	insertLandFixture(t, db)
+
+	// NULL과 빈 full_code는 함께 맨 앞, 같은 full_code는 id 순서
+	want := []string{"U2", "U4", "U9", "U1", "U3", "U7", "U8"}
+
+	// 배치 경계가 같은 full_code 안, 사이, 끝에 걸리는 크기를 모두 확인
+	for batchSize := 1; batchSize <= len(want)+1; batchSize++ {
+		records := make(map[string]LandAddress)
+		var got []string
+		err := LoadLandAddressesBatch(context.Background(), db, batchSize, func(batch []LandAddress) error {
+			if len(batch) > batchSize {
+				t.Errorf("batch size %d: got a batch of %d", batchSize, len(batch))
+			}
+			for _, record := range batch {
+				got = append(got, record.UniqueNo)
+				records[record.UniqueNo] = record
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("batch size %d: LoadLandAddressesBatch() error = %v", batchSize, err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("batch size %d: unique_no order = %v, want %v", batchSize, got, want)
+		}
+
+		if record := records["U1"]; record.RoadAddress != "서울특별시 강남구 테헤란로 1" || record.FullCode != "1168010100" ||
+			record.Lat != 37.5 || record.Lng != 127.0 {
+			t.Errorf("batch size %d: U1 = %+v", batchSize, record)
+		}
+		if record := records["U4"]; record.RoadAddress != "" || record.FullCode != "" || record.Lat != 0 || record.Lng != 0 {
+			t.Errorf("batch size %d: U4 = %+v", batchSize, record)
+		}
+	}
+}
+
+func TestLoadLandAddressesBatchPostgresCancel(t *testing.T) {
+	db := openTestDB(t)
+	insertLandFixture(t, db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := 0
+	err := LoadLandAddressesBatch(ctx, db, 2, func([]LandAddress) error {
+		batches++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadLandAddressesBatch() error = %v, want context.Canceled", err)
+	}
+	if batches != 1 {
+		t.Errorf("processed %d batches after cancel, want 1", batches)
+	}
+}
+
+func TestLoadLandChangesPostgres(t *testing.T) {
+	db := openTestDB(t)
+	if err := CheckLandTombstones(context.Background(), db); err != nil {
+		t.Fatalf("CheckLandTombstones() error = %v", err)
+	}
+
+	at := func(second int) time.Time { return time.Date(2024, 1, 1, 0, 0, second, 0, time.UTC) }
+	execSQL(t, db, `INSERT INTO land (id, unique_no, full_code, address, road_address, updated_at) VALUES
+		(1, 'U1', '1168010100', '서울특별시 강남구 역삼동 1', '서울특별시 강남구 테헤란로 1', $1),
+		(2, 'U2', '1168010100', '서울특별시 강남구 역삼동 2', NULL, $2),
+		(3, 'U3', '1168010100', NULL, NULL, $3)`, at(1), at(2), at(3))
+	execSQL(t, db, `INSERT INTO land_tombstone (id, unique_no, deleted_at) VALUES (10, 'U10', $1)`, at(4))
+
+	watermark, err := CurrentWatermark(context.Background(), db)
+	if err != nil {
+		t.Fatalf("CurrentWatermark() error = %v", err)
+	}
+	if !watermark.UpdatedAt.Equal(at(4)) || watermark.ID != 10 {
+		t.Errorf("CurrentWatermark() = %+v, want the tombstone at %s", watermark, at(4))
+	}
+
+	var changes []LandChange
+	watermark, err = LoadLandChanges(context.Background(), db, Watermark{UpdatedAt: at(1), ID: 1}, 2, func(batch []LandChange) error {
+		changes = append(changes, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("LoadLandChanges() error = %v", err)
+	}
+	if !watermark.UpdatedAt.Equal(at(4)) || watermark.ID != 10 {
+		t.Errorf("LoadLandChanges() watermark = %+v, want the tombstone at %s", watermark, at(4))
+	}
+
+	want := []LandChange{
+		{LandAddress: LandAddress{UniqueNo: "U2", FullCode: "1168010100", Address: "서울특별시 강남구 역삼동 2"}},
+		{LandAddress: LandAddress{UniqueNo: "U3", FullCode: "1168010100"}, Deleted: true},
+		{LandAddress: LandAddress{UniqueNo: "U10"}, Deleted: true},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("LoadLandChanges() = %+v, want %+v", changes, want)
+	}
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// landRow is a row of the land table served by landStub
+type landRow struct {
+	id          int64
+	address     string
+	uniqueNo    string
+	fullCode    *string
+	roadAddress string
+}
+
+// landStub is a database/sql driver answering the keyset query of
+// LoadLandAddressesBatch from rows. Like PostgreSQL it returns as many columns
+// as the query selects, so a Scan that does not match the SELECT list fails.
+type landStub struct {
+	rows    []landRow
+	queries int
+}
+
+func (stub *landStub) Open(string) (driver.Conn, error)             { return stub, nil }
+func (stub *landStub) Connect(context.Context) (driver.Conn, error) { return stub, nil }
+func (stub *landStub) Driver() driver.Driver                        { return stub }
+func (stub *landStub) Close() error                                 { return nil }
+func (stub *landStub) Begin() (driver.Tx, error)                    { return nil, errors.New("not supported") }
+
+func (stub *landStub) Prepare(query string) (driver.Stmt, error) {
+	return &landStmt{stub: stub, query: query}, nil
+}
+
+type landStmt struct {
+	stub  *landStub
+	query string
+}
+
+func (stmt *landStmt) Close() error  { return nil }
+func (stmt *landStmt) NumInput() int { return -1 }
+
+func (stmt *landStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+// Query serves the rows after the ($2, $3) key in (full_code, id) order, up to $1
+func (stmt *landStmt) Query(args []driver.Value) (driver.Rows, error) {
+	stmt.stub.queries++
+	limit, lastFullCode, lastID := args[0].(int64), args[1].(string), args[2].(int64)
+
+	sorted := slices.Clone(stmt.stub.rows)
+	slices.SortFunc(sorted, func(a, b landRow) int {
+		if c := strings.Compare(coalesce(a.fullCode), coalesce(b.fullCode)); c != 0 {
+			return c
+		}
+		return int(a.id - b.id)
+	})
+
+	rows := &landRows{columns: selectColumns(stmt.query)}
+	for _, row := range sorted {
+		fullCode := coalesce(row.fullCode)
+		if row.address == "" || fullCode < lastFullCode || fullCode == lastFullCode && row.id <= lastID {
+			continue
+		}
+		if int64(len(rows.values)) == limit {
+			break
+		}
+		values := []driver.Value{row.id, row.address, row.uniqueNo, fullCode, 37.5, 127.0, row.roadAddress}
+		rows.values = append(rows.values, values[:min(len(values), len(rows.columns))])
+	}
+	return rows, nil
+}
+
+type landRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (rows *landRows) Columns() []string { return rows.columns }
+func (rows *landRows) Close() error      { return nil }
+
+func (rows *landRows) Next(dest []driver.Value) error {
+	if len(rows.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, rows.values[0])
+	rows.values = rows.values[1:]
+	return nil
+}
+
+// selectColumns splits the SELECT list of query at the commas outside parentheses
+func selectColumns(query string) []string {
+	list := query[strings.Index(query, "SELECT")+len("SELECT") : strings.Index(query, "FROM")]
+
+	columns := make([]string, 0)
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				columns = append(columns, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(columns, strings.TrimSpace(list[start:]))
+}
+
+func coalesce(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func TestLoadLandAddressesBatch(t *testing.T) {
+	code := func(value string) *string { return &value }
+	stub := &landStub{rows: []landRow{
+		{id: 5, address: "서울특별시 강남구 역삼동 3", uniqueNo: "U5", fullCode: code("1168010100"), roadAddress: "서울특별시 강남구 테헤란로 3"},
+		{id: 1, address: "서울특별시 강남구 역삼동 1", uniqueNo: "U1", fullCode: code("1168010100")},
+		{id: 3, address: "서울특별시 강남구 역삼동 2", uniqueNo: "U3", fullCode: code("1168010100")},
+		{id: 2, address: "", uniqueNo: "U2", fullCode: code("1168010100")},
+		{id: 7, address: "세종특별자치시 반곡동 1", uniqueNo: "U7"},
+		{id: 4, address: "세종특별자치시 반곡동 2", uniqueNo: "U4"},
+		{id: 6, address: "서울특별시 강남구 개포동 1", uniqueNo: "U6", fullCode: code("1168010300"), roadAddress: "서울특별시 강남구 개포로 1"},
+	}}
+	db := sql.OpenDB(stub)
+	defer db.Close()
+
+	var batches [][]LandAddress
+	err := LoadLandAddressesBatch(context.Background(), db, 2, func(batch []LandAddress) error {
+		batches = append(batches, batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("LoadLandAddressesBatch() error = %v", err)
+	}
+
+	// NULL full_code가 먼저, 같은 full_code는 id 순서로 배치 경계를 넘어도 빠짐없이
+	want := []string{"U4", "U7", "U1", "U3", "U5", "U6"}
+	var got []string
+	for _, batch := range batches {
+		if len(batch) > 2 {
+			t.Errorf("batch of %d addresses, want at most 2", len(batch))
+		}
+		for _, record := range batch {
+			got = append(got, record.UniqueNo)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("unique_no order = %v, want %v", got, want)
+	}
+	if stub.queries != 4 {
+		t.Errorf("queries = %d, want 4", stub.queries)
+	}
+
+	lastBatch := batches[len(batches)-1]
+	last := lastBatch[len(lastBatch)-1]
+	if last.FullCode != "1168010300" || last.RoadAddress != "서울특별시 강남구 개포로 1" || last.Lat != 37.5 || last.Lng != 127.0 {
+		t.Errorf("last address = %+v", last)
+	}
+}
+
+func TestLoadLandAddressesBatchCancel(t *testing.T) {
+	stub := &landStub{rows: []landRow{
+		{id: 1, address: "서울특별시 강남구 역삼동 1", uniqueNo: "U1"},
+		{id: 2, address: "서울특별시 강남구 역삼동 2", uniqueNo: "U2"},
+		{id: 3, address: "서울특별시 강남구 역삼동 3", uniqueNo: "U3"},
+	}}
+	db := sql.OpenDB(stub)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := 0
+	err := LoadLandAddressesBatch(ctx, db, 1, func([]LandAddress) error {
+		batches++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadLandAddressesBatch() error = %v, want context.Canceled", err)
+	}
+	if batches != 1 {
+		t.Errorf("processed %d batches after cancel, want 1", batches)
+	}
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval bounds how long a dead connection goes unnoticed
+	listenerPingInterval = 90 * time.Second
+)
+
+// landNotification is the JSON payload built by the land_notify trigger
+type landNotification struct {
+	Op          string   `json:"op"`
+	UniqueNo    string   `json:"unique_no"`
+	Address     *string  `json:"address"`
+	FullCode    *string  `json:"full_code"`
+	Lat         *float64 `json:"lat"`
+	Lng         *float64 `json:"lng"`
+	RoadAddress *string  `json:"road_address"`
+}
+
+// ParseLandNotification decodes a land_notify payload. A DELETE, or a row
+// whose address was cleared, becomes a deletion, and a null road_address
+// removes the road-name address.
+func ParseLandNotification(payload string) (LandChange, error) {
+	var notification landNotification
+	if err := json.Unmarshal([]byte(payload), &notification); err != nil {
+		return LandChange{}, fmt.Errorf("failed to decode land notification: %w", err)
+	}
+	if notification.UniqueNo == "" {
+		return LandChange{}, fmt.Errorf("land notification without unique_no: %s", payload)
+	}
+
+	change := LandChange{LandAddress: LandAddress{UniqueNo: notification.UniqueNo}}
+	if notification.Address != nil {
+		change.Address = *notification.Address
+	}
+	if notification.FullCode != nil {
+		change.FullCode = *notification.FullCode
+	}
+	if notification.RoadAddress != nil {
+		change.RoadAddress = *notification.RoadAddress
+	}
+	if notification.Lat != nil && notification.Lng != nil {
+		change.Lat = *notification.Lat
+		change.Lng = *notification.Lng
+	}
+	change.Deleted = notification.Op == "DELETE" || change.Address == ""
+
+	return change, nil
+}
+
+// LandListener receives the changes published by the land_notify trigger
+type LandListener struct {
+	listener *pq.Listener
+	channel  string
+}
+
+// NewLandListener connects to the database and subscribes to channel
+func NewLandListener(config Config, channel string) (*LandListener, error) {
+	listener := pq.NewListener(config.connString(), listenerMinReconnect, listenerMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				log.Printf("Land listener disconnected: %v", err)
+			case pq.ListenerEventReconnected:
+				log.Println("Land listener reconnected")
+			case pq.ListenerEventConnectionAttemptFailed:
+				log.Printf("Land listener failed to reconnect: %v", err)
+			}
+		})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+
+	log.Printf("Listening for land changes on %s", channel)
+	return &LandListener{listener: listener, channel: channel}, nil
+}
+
+// Run hands every change to onChange until ctx is cancelled. A lost connection
+// is re-established with backoff, and onReconnect is called afterwards so the
+// caller can catch up on the notifications missed in between.
+func (l *LandListener) Run(ctx context.Context, onChange func(LandChange), onReconnect func()) error {
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case notification := <-l.listener.Notify:
+			// 재연결되면 nil이 오므로 놓친 변경을 따라잡기
+			if notification == nil {
+				onReconnect()
+				continue
+			}
+
+			change, err := ParseLandNotification(notification.Extra)
+			if err != nil {
+				log.Printf("Skipping land notification on %s: %v", l.channel, err)
+				continue
+			}
+			onChange(change)
+		case <-ticker.C:
+			// 끊긴 연결을 알아차릴 수 있도록 주기적으로 확인
+			go l.listener.Ping()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close stops listening and closes the connection
+func (l *LandListener) Close() error {
+	return l.listener.Close()
+}
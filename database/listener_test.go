@@ -0,0 +1,59 @@
+package database
+
+import "testing"
+
+func TestParseLandNotification(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    LandChange
+	}{
+		{
+			name:    "road address",
+			payload: `{"op":"UPDATE","unique_no":"U1","address":"서울특별시 강남구 역삼동 737","road_address":"서울특별시 강남구 테헤란로 152","lat":37.5,"lng":127.0}`,
+			want: LandChange{
+				LandAddress: LandAddress{UniqueNo: "U1", Address: "서울특별시 강남구 역삼동 737", RoadAddress: "서울특별시 강남구 테헤란로 152", Lat: 37.5, Lng: 127.0},
+			},
+		},
+		{
+			name:    "road address cleared",
+			payload: `{"op":"UPDATE","unique_no":"U1","address":"서울특별시 강남구 역삼동 737","road_address":null}`,
+			want: LandChange{
+				LandAddress: LandAddress{UniqueNo: "U1", Address: "서울특별시 강남구 역삼동 737"},
+			},
+		},
+		{
+			name:    "delete",
+			payload: `{"op":"DELETE","unique_no":"U1","address":"서울특별시 강남구 역삼동 737","road_address":null}`,
+			want: LandChange{
+				LandAddress: LandAddress{UniqueNo: "U1", Address: "서울특별시 강남구 역삼동 737"},
+				Deleted:     true,
+			},
+		},
+		{
+			name:    "address cleared",
+			payload: `{"op":"UPDATE","unique_no":"U1","address":null,"road_address":null}`,
+			want:    LandChange{LandAddress: LandAddress{UniqueNo: "U1"}, Deleted: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLandNotification(tt.payload)
+			if err != nil {
+				t.Fatalf("ParseLandNotification() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLandNotification() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLandNotificationInvalid(t *testing.T) {
+	for _, payload := range []string{`{"op":"UPDATE","address":"역삼동 1"}`, `{"unique_no":"U1","road_address":1}`, `not json`} {
+		if _, err := ParseLandNotification(payload); err == nil {
+			t.Errorf("ParseLandNotification(%s) succeeded, want error", payload)
+		}
+	}
+}
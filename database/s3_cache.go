@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// s3CacheEntry describes the object version stored next to a cached file
+type s3CacheEntry struct {
+	ETag      string `json:"etag"`
+	VersionID string `json:"version_id,omitempty"`
+}
+
+// cachedS3Object returns the path of a local copy of object in config.CacheDir.
+// The copy is refreshed with a conditional GetObject (If-None-Match) so an
+// unchanged object is not downloaded again.
+func cachedS3Object(ctx context.Context, client *s3.S3, config S3Config, object s3Object) (string, error) {
+	if err := os.MkdirAll(config.CacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	path := filepath.Join(config.CacheDir, url.PathEscape(config.Bucket+"/"+object.Key))
+	cached, ok := readCacheEntry(path)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(config.Bucket),
+		Key:    aws.String(object.Key),
+	}
+	if ok {
+		input.IfNoneMatch = aws.String(cached.ETag)
+	}
+
+	output, err := client.GetObjectWithContext(ctx, input)
+	if isNotModified(err) {
+		log.Printf("Using cached copy of %s (ETag %s)", object.Key, cached.ETag)
+		return path, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download file from S3: %w", err)
+	}
+	defer output.Body.Close()
+
+	log.Printf("Downloading %s from S3 into cache...", object.Key)
+
+	// 임시 파일에 받은 뒤 교체해 중간에 실패해도 기존 캐시 유지
+	file, err := os.CreateTemp(config.CacheDir, ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	numBytes, err := io.Copy(file, output.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	// 파일을 교체하기 전에 이전 메타데이터부터 제거
+	if err := os.Remove(path + ".meta"); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to remove cache metadata: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to replace cache file: %w", err)
+	}
+
+	entry := s3CacheEntry{
+		ETag:      aws.StringValue(output.ETag),
+		VersionID: aws.StringValue(output.VersionId),
+	}
+	if err := writeCacheEntry(path, entry); err != nil {
+		return "", err
+	}
+
+	log.Printf("Cached %s (%d bytes, ETag %s)", object.Key, numBytes, entry.ETag)
+	return path, nil
+}
+
+// readCacheEntry returns the metadata of a cached file, if both exist
+func readCacheEntry(path string) (s3CacheEntry, bool) {
+	if _, err := os.Stat(path); err != nil {
+		return s3CacheEntry{}, false
+	}
+
+	data, err := os.ReadFile(path + ".meta")
+	if err != nil {
+		return s3CacheEntry{}, false
+	}
+
+	var entry s3CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ETag == "" {
+		return s3CacheEntry{}, false
+	}
+	return entry, true
+}
+
+func writeCacheEntry(path string, entry s3CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache metadata: %w", err)
+	}
+	if err := os.WriteFile(path+".meta", data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache metadata: %w", err)
+	}
+	return nil
+}
+
+// isNotModified reports whether a conditional request found the object unchanged
+func isNotModified(err error) bool {
+	var requestErr awserr.RequestFailure
+	return errors.As(err, &requestErr) && requestErr.StatusCode() == http.StatusNotModified
+}
@@ -3,11 +3,17 @@ package database
 import (
 	"archive/zip"
 	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -16,157 +22,276 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
+// Defaults used when the S3 location is not configured
 const (
-	S3Bucket = "izza-test-data"
-	S3Key    = "land-address/extracted_addresses.zip"
-	S3Region = "ap-northeast-2"
-	TempDir  = "/tmp/land-addresses"
-	ZipFile  = "/tmp/land-addresses.zip"
+	DefaultS3Bucket = "izza-test-data"
+	DefaultS3Key    = "land-address/extracted_addresses.zip"
+	DefaultS3Region = "ap-northeast-2"
+	// DefaultS3MaxBufferSize is the largest archive downloaded into memory;
+	// larger archives are read with ranged GetObject requests instead
+	DefaultS3MaxBufferSize = 256 << 20
 )
 
-func LoadLandAddressesFromS3Batch(batchSize int, processor func([]string) error) error {
+// S3Config locates the address objects in S3. Every object named in Keys and
+// every object under Prefixes is read; .zip objects are scanned entry by entry
+// and any other object is read as a text file.
+type S3Config struct {
+	Bucket   string
+	Keys     []string
+	Prefixes []string
+	Region   string
+	// Endpoint overrides the S3 endpoint, e.g. a local MinIO
+	Endpoint string
+	// ForcePathStyle addresses objects as endpoint/bucket/key
+	ForcePathStyle bool
+	MaxBufferSize  int64
+	// CacheDir keeps downloaded objects between loads and refreshes them with
+	// conditional requests; objects are streamed without touching disk when empty
+	CacheDir string
+}
+
+// DefaultS3Config returns the configuration of the original dataset
+func DefaultS3Config() S3Config {
+	return S3Config{
+		Bucket:        DefaultS3Bucket,
+		Keys:          []string{DefaultS3Key},
+		Region:        DefaultS3Region,
+		MaxBufferSize: DefaultS3MaxBufferSize,
+	}
+}
+
+// s3Object is a single object selected by S3Config
+type s3Object struct {
+	Key       string
+	ETag      string
+	VersionID string
+	Size      int64
+}
+
+// LoadLandAddressesFromS3Batch reads the configured objects straight from S3
+// without writing anything to disk.
+func LoadLandAddressesFromS3Batch(ctx context.Context, config S3Config, batchSize int, processor func([]LandAddress) error) error {
 	if batchSize <= 0 {
 		batchSize = DefaultBatchSize
 	}
+	processor = withContext(ctx, processor)
 
-	// S3에서 ZIP 파일 다운로드
-	if err := downloadZipFromS3(); err != nil {
-		return fmt.Errorf("failed to download ZIP from S3: %w", err)
+	client, err := newS3Client(config)
+	if err != nil {
+		return err
 	}
 
-	// ZIP 파일 압축 해제
-	if err := extractZip(); err != nil {
-		return fmt.Errorf("failed to extract ZIP file: %w", err)
+	// 키와 프리픽스로 읽을 객체 목록 만들기
+	objects, err := listS3Objects(ctx, client, config)
+	if err != nil {
+		return err
 	}
-
-	// TXT 파일들에서 주소 데이터 읽기
-	if err := processTextFiles(batchSize, processor); err != nil {
-		return fmt.Errorf("failed to process text files: %w", err)
+	if len(objects) == 0 {
+		return fmt.Errorf("no objects found in s3://%s", config.Bucket)
 	}
 
-	// 임시 파일들 정리
-	cleanupTempFiles()
+	log.Printf("Found %d objects to process in s3://%s", len(objects), config.Bucket)
 
-	return nil
-}
-
-func downloadZipFromS3() error {
-	log.Println("Downloading ZIP file from S3...")
+	totalProcessed := 0
+	batch := make([]LandAddress, 0, batchSize)
 
-	// AWS 세션 생성
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(S3Region),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to create AWS session: %w", err)
+	for _, object := range objects {
+		if err := processS3Object(ctx, client, config, object, batchSize, &batch, processor, &totalProcessed); err != nil {
+			return fmt.Errorf("failed to process s3://%s/%s: %w", config.Bucket, object.Key, err)
+		}
 	}
 
-	// 임시 디렉토리 생성
-	if err := os.MkdirAll(filepath.Dir(ZipFile), 0755); err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
-	}
+	// 남은 배치 처리
+	return flushBatch(batch, processor, totalProcessed)
+}
 
-	// 파일 생성
-	file, err := os.Create(ZipFile)
+// S3Version returns a digest of the ETags and version IDs of the objects
+// selected by config, which changes whenever any of them changes
+func S3Version(ctx context.Context, config S3Config) (string, error) {
+	client, err := newS3Client(config)
 	if err != nil {
-		return fmt.Errorf("failed to create ZIP file: %w", err)
+		return "", err
 	}
-	defer file.Close()
-
-	// S3 다운로더 생성
-	downloader := s3manager.NewDownloader(sess)
 
-	// S3에서 파일 다운로드
-	numBytes, err := downloader.Download(file, &s3.GetObjectInput{
-		Bucket: aws.String(S3Bucket),
-		Key:    aws.String(S3Key),
-	})
+	objects, err := listS3Objects(ctx, client, config)
 	if err != nil {
-		return fmt.Errorf("failed to download file from S3: %w", err)
+		return "", err
 	}
 
-	log.Printf("ZIP file downloaded successfully (%d bytes)", numBytes)
-	return nil
+	hash := sha256.New()
+	for _, object := range objects {
+		fmt.Fprintf(hash, "%s\t%s\t%s\n", object.Key, object.ETag, object.VersionID)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-func extractZip() error {
-	log.Println("Extracting ZIP file...")
+// newS3Client creates a client for the configured region and endpoint
+func newS3Client(config S3Config) (*s3.S3, error) {
+	awsConfig := &aws.Config{
+		Region:           aws.String(config.Region),
+		S3ForcePathStyle: aws.Bool(config.ForcePathStyle),
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
 
-	// ZIP 파일 열기
-	r, err := zip.OpenReader(ZipFile)
+	// AWS 세션 생성
+	sess, err := session.NewSession(awsConfig)
 	if err != nil {
-		return fmt.Errorf("failed to open ZIP file: %w", err)
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
 	}
-	defer r.Close()
 
-	// 압축 해제 디렉토리 생성
-	if err := os.MkdirAll(TempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create extraction directory: %w", err)
-	}
+	return s3.New(sess), nil
+}
+
+// listS3Objects resolves Keys and Prefixes into objects, skipping duplicates,
+// "directories" and hidden files
+func listS3Objects(ctx context.Context, client *s3.S3, config S3Config) ([]s3Object, error) {
+	var objects []s3Object
+	seen := make(map[string]bool)
 
-	// 각 파일 압축 해제
-	for _, f := range r.File {
-		// 디렉토리 트래버셜 공격 방지
-		if strings.Contains(f.Name, "..") {
+	for _, key := range config.Keys {
+		if seen[key] {
 			continue
 		}
+		head, err := client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(config.Bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata of s3://%s/%s: %w", config.Bucket, key, err)
+		}
+		seen[key] = true
+		objects = append(objects, s3Object{
+			Key:       key,
+			ETag:      aws.StringValue(head.ETag),
+			VersionID: aws.StringValue(head.VersionId),
+			Size:      aws.Int64Value(head.ContentLength),
+		})
+	}
 
-		rc, err := f.Open()
+	for _, prefix := range config.Prefixes {
+		err := client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+			Bucket: aws.String(config.Bucket),
+			Prefix: aws.String(prefix),
+		}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+			for _, item := range page.Contents {
+				key := aws.StringValue(item.Key)
+				name := path.Base(key)
+				if seen[key] || strings.HasSuffix(key, "/") || strings.HasPrefix(name, ".") {
+					continue
+				}
+				seen[key] = true
+				objects = append(objects, s3Object{
+					Key:  key,
+					ETag: aws.StringValue(item.ETag),
+					Size: aws.Int64Value(item.Size),
+				})
+			}
+			return true
+		})
 		if err != nil {
-			return fmt.Errorf("failed to open file %s in ZIP: %w", f.Name, err)
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", config.Bucket, prefix, err)
 		}
+	}
+
+	return objects, nil
+}
 
-		path := filepath.Join(TempDir, f.Name)
+// processS3Object scans a single object, entry by entry for ZIP archives
+func processS3Object(ctx context.Context, client *s3.S3, config S3Config, object s3Object, batchSize int, batch *[]LandAddress, processor func([]LandAddress) error, totalProcessed *int) error {
+	isZip := strings.HasSuffix(strings.ToLower(object.Key), ".zip")
 
-		// 디렉토리인 경우
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(path, f.FileInfo().Mode())
-			rc.Close()
-			continue
+	// 캐시가 설정되어 있으면 바뀐 경우에만 내려받고 로컬 사본을 읽기
+	if config.CacheDir != "" {
+		cachePath, err := cachedS3Object(ctx, client, config, object)
+		if err != nil {
+			return err
 		}
-
-		// 파일인 경우
-		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-			rc.Close()
-			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		if isZip {
+			r, err := zip.OpenReader(cachePath)
+			if err != nil {
+				return fmt.Errorf("failed to open ZIP file: %w", err)
+			}
+			defer r.Close()
+			return processZipReader(&r.Reader, batchSize, batch, processor, totalProcessed)
 		}
+		return processTextFile(cachePath, batchSize, batch, processor, totalProcessed)
+	}
 
-		outFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.FileInfo().Mode())
+	if !isZip {
+		output, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+			Bucket:  aws.String(config.Bucket),
+			Key:     aws.String(object.Key),
+			IfMatch: ifMatch(object.ETag),
+		})
 		if err != nil {
-			rc.Close()
-			return fmt.Errorf("failed to create file %s: %w", path, err)
+			return fmt.Errorf("failed to read file from S3: %w", err)
 		}
+		defer output.Body.Close()
 
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
+		return scanTextFile(output.Body, object.Key, batchSize, batch, processor, totalProcessed)
+	}
 
-		if err != nil {
-			return fmt.Errorf("failed to write file %s: %w", path, err)
-		}
+	// S3의 ZIP 파일을 ReaderAt으로 열기
+	readerAt, err := openZipFromS3(ctx, client, config, object)
+	if err != nil {
+		return fmt.Errorf("failed to open ZIP from S3: %w", err)
 	}
 
-	log.Println("ZIP file extracted successfully")
-	return nil
+	r, err := zip.NewReader(readerAt, object.Size)
+	if err != nil {
+		return fmt.Errorf("failed to read ZIP file: %w", err)
+	}
+
+	// ZIP 안의 TXT 파일들에서 주소 데이터 읽기
+	return processZipReader(r, batchSize, batch, processor, totalProcessed)
 }
 
-func processTextFiles(batchSize int, processor func([]string) error) error {
+// openZipFromS3 returns a ReaderAt over the object. Archives up to
+// MaxBufferSize are downloaded into memory, larger ones are read lazily
+// with ranged requests pinned to the object's ETag.
+func openZipFromS3(ctx context.Context, client *s3.S3, config S3Config, object s3Object) (io.ReaderAt, error) {
+	if object.Size > config.MaxBufferSize {
+		log.Printf("Reading ZIP file %s from S3 with ranged requests (%d bytes)", object.Key, object.Size)
+		return newS3ReaderAt(ctx, client, config.Bucket, object.Key, object.ETag, object.Size), nil
+	}
+
+	log.Printf("Downloading ZIP file %s from S3 into memory...", object.Key)
+
+	// S3 다운로더로 메모리 버퍼에 받기
+	buffer := aws.NewWriteAtBuffer(make([]byte, 0, object.Size))
+	downloader := s3manager.NewDownloaderWithClient(client)
+	numBytes, err := downloader.DownloadWithContext(ctx, buffer, &s3.GetObjectInput{
+		Bucket:  aws.String(config.Bucket),
+		Key:     aws.String(object.Key),
+		IfMatch: ifMatch(object.ETag),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from S3: %w", err)
+	}
+
+	log.Printf("ZIP file downloaded successfully (%d bytes)", numBytes)
+	return bytes.NewReader(buffer.Bytes()), nil
+}
+
+func processTextFiles(dir string, batchSize int, processor func([]LandAddress) error) error {
 	log.Println("Processing text files...")
 
 	// TXT 파일들 찾기
-	txtFiles, err := findTextFiles(TempDir)
+	txtFiles, err := findTextFiles(dir)
 	if err != nil {
 		return fmt.Errorf("failed to find text files: %w", err)
 	}
 
 	if len(txtFiles) == 0 {
-		return fmt.Errorf("no text files found in extracted directory")
+		return fmt.Errorf("no text files found in %s", dir)
 	}
 
 	log.Printf("Found %d text files to process", len(txtFiles))
 
 	totalProcessed := 0
-	batch := make([]string, 0, batchSize)
+	batch := make([]LandAddress, 0, batchSize)
 
 	// 각 TXT 파일 처리
 	for _, txtFile := range txtFiles {
@@ -176,16 +301,7 @@ func processTextFiles(batchSize int, processor func([]string) error) error {
 	}
 
 	// 남은 배치 처리
-	if len(batch) > 0 {
-		if err := processor(batch); err != nil {
-			return fmt.Errorf("failed to process final batch: %w", err)
-		}
-		totalProcessed += len(batch)
-		log.Printf("Processed final batch: %d addresses", len(batch))
-	}
-
-	log.Printf("Total addresses processed: %d", totalProcessed)
-	return nil
+	return flushBatch(batch, processor, totalProcessed)
 }
 
 func findTextFiles(dir string) ([]string, error) {
@@ -214,32 +330,33 @@ func findTextFiles(dir string) ([]string, error) {
 	return txtFiles, err
 }
 
-func processTextFile(filename string, batchSize int, batch *[]string, processor func([]string) error, totalProcessed *int) error {
+func processTextFile(filename string, batchSize int, batch *[]LandAddress, processor func([]LandAddress) error, totalProcessed *int) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %w", filename, err)
 	}
 	defer file.Close()
 
+	return scanTextFile(file, filename, batchSize, batch, processor, totalProcessed)
+}
+
+// scanTextFile reads address lines from r, handing full batches to processor
+func scanTextFile(r io.Reader, filename string, batchSize int, batch *[]LandAddress, processor func([]LandAddress) error, totalProcessed *int) error {
 	log.Printf("Processing file: %s", filename)
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	fileProcessed := 0
 
 	for scanner.Scan() {
 		rawLine := scanner.Text()
-		address := strings.TrimSpace(rawLine)
+		record, ok := parseAddressLine(rawLine)
 
 		// 디버그 로그: 문제가 될 수 있는 라인들 출력
-		if len(address) < 2 {
+		if !ok {
 			continue
 		}
 
-		// 특수문자나 제어문자 확인
-		if len(address) != len(strings.TrimSpace(strings.ReplaceAll(address, " ", ""))) {
-		}
-
-		*batch = append(*batch, address)
+		*batch = append(*batch, record)
 		fileProcessed++
 
 		// 배치가 가득 찼으면 처리
@@ -265,18 +382,41 @@ func processTextFile(filename string, batchSize int, batch *[]string, processor
 	return nil
 }
 
-func cleanupTempFiles() {
-	log.Println("Cleaning up temporary files...")
+// parseAddressLine parses a tab separated line of
+// "address[\tweight[\tunique_no\tfull_code\tlat\tlng[\troad_address]]]".
+// Missing or malformed optional columns are left empty.
+func parseAddressLine(line string) (LandAddress, bool) {
+	fields := strings.Split(line, "\t")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
 
-	// ZIP 파일 삭제
-	if err := os.Remove(ZipFile); err != nil {
-		log.Printf("Warning: failed to remove ZIP file: %v", err)
+	address := fields[0]
+	if len(address) < 2 {
+		return LandAddress{}, false
 	}
 
-	// 압축 해제된 디렉토리 삭제
-	if err := os.RemoveAll(TempDir); err != nil {
-		log.Printf("Warning: failed to remove temp directory: %v", err)
+	record := LandAddress{Address: address}
+	if len(fields) > 1 {
+		if weight, err := strconv.ParseFloat(fields[1], 32); err == nil {
+			record.Weight = float32(weight)
+		}
+	}
+	if len(fields) > 3 {
+		record.UniqueNo = fields[2]
+		record.FullCode = fields[3]
+	}
+	if len(fields) > 5 {
+		lat, latErr := strconv.ParseFloat(fields[4], 64)
+		lng, lngErr := strconv.ParseFloat(fields[5], 64)
+		if latErr == nil && lngErr == nil {
+			record.Lat = lat
+			record.Lng = lng
+		}
+	}
+	if len(fields) > 6 {
+		record.RoadAddress = fields[6]
 	}
 
-	log.Println("Cleanup completed")
+	return record, true
 }
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// s3BlockSize is the size of each ranged GetObject request
+const s3BlockSize = 8 << 20
+
+// s3ReaderAt reads an S3 object with ranged GetObject requests, keeping the
+// last block in memory so sequential reads of a ZIP entry stay cheap.
+type s3ReaderAt struct {
+	ctx    context.Context
+	client *s3.S3
+	bucket string
+	key    string
+	etag   string
+	size   int64
+
+	mu          sync.Mutex
+	block       []byte
+	blockOffset int64
+}
+
+func newS3ReaderAt(ctx context.Context, client *s3.S3, bucket, key, etag string, size int64) *s3ReaderAt {
+	return &s3ReaderAt{
+		ctx:    ctx,
+		client: client,
+		bucket: bucket,
+		key:    key,
+		etag:   etag,
+		size:   size,
+	}
+}
+
+func (r *s3ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+
+		// 캐시된 블록 밖이면 새 블록 요청
+		if pos < r.blockOffset || pos >= r.blockOffset+int64(len(r.block)) {
+			if err := r.fetch(pos); err != nil {
+				return n, err
+			}
+		}
+
+		n += copy(p[n:], r.block[pos-r.blockOffset:])
+	}
+
+	return n, nil
+}
+
+// fetch loads the block that starts at offset
+func (r *s3ReaderAt) fetch(offset int64) error {
+	length := min(int64(s3BlockSize), r.size-offset)
+
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(r.bucket),
+		Key:    aws.String(r.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	}
+	// 읽는 도중 객체가 바뀌면 실패하도록 ETag 고정
+	input.IfMatch = ifMatch(r.etag)
+
+	output, err := r.client.GetObjectWithContext(r.ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to read bytes %d-%d from S3: %w", offset, offset+length-1, err)
+	}
+	defer output.Body.Close()
+
+	if cap(r.block) < int(length) {
+		r.block = make([]byte, length)
+	}
+	r.block = r.block[:length]
+	r.blockOffset = offset
+
+	if _, err := io.ReadFull(output.Body, r.block); err != nil {
+		r.block = r.block[:0]
+		return fmt.Errorf("failed to read bytes %d-%d from S3: %w", offset, offset+length-1, err)
+	}
+
+	return nil
+}
+
+// ifMatch pins a request to etag, or leaves it unconditional when etag is unknown
+func ifMatch(etag string) *string {
+	if etag == "" {
+		return nil
+	}
+	return aws.String(etag)
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrSourceConsumed is returned when a source that can be read only once,
+// such as stdin, is loaded again
+var ErrSourceConsumed = errors.New("address source can only be loaded once")
+
+// AddressSource streams land address records in batches
+type AddressSource interface {
+	// Name describes the source in logs
+	Name() string
+	// Load hands batches of at most batchSize records to processor until the
+	// source is exhausted, processor returns an error or ctx is cancelled
+	Load(ctx context.Context, batchSize int, processor func([]LandAddress) error) error
+}
+
+// VersionedSource is an AddressSource that can report the version of its data
+// without loading it, so an unchanged source does not need a rebuild
+type VersionedSource interface {
+	AddressSource
+	// Version returns a token that changes whenever the data changes
+	Version(ctx context.Context) (string, error)
+}
+
+// Source kinds accepted by NewAddressSource
+const (
+	SourceS3       = "s3"
+	SourcePostgres = "postgres"
+	SourceFiles    = "files"
+	SourceStdin    = "stdin"
+)
+
+// SourceConfig selects and configures an AddressSource
+type SourceConfig struct {
+	// Kind is one of SourceS3, SourcePostgres, SourceFiles or SourceStdin
+	Kind string
+	// Path is the directory, .txt or .zip file read by SourceFiles
+	Path string
+	// S3 locates the objects read by SourceS3
+	S3 S3Config
+	// DB is the connection used by SourcePostgres
+	DB Config
+}
+
+// NewAddressSource returns the source described by config
+func NewAddressSource(config SourceConfig) (AddressSource, error) {
+	switch config.Kind {
+	case SourceS3, "":
+		if config.S3.Bucket == "" || len(config.S3.Keys)+len(config.S3.Prefixes) == 0 {
+			return nil, fmt.Errorf("a bucket and at least one key or prefix are required for the %s source", SourceS3)
+		}
+		return S3Source{Config: config.S3}, nil
+	case SourcePostgres:
+		return PostgresSource{Config: config.DB}, nil
+	case SourceFiles:
+		if config.Path == "" {
+			return nil, fmt.Errorf("a path is required for the %s source", SourceFiles)
+		}
+		return FileSource{Path: config.Path}, nil
+	case SourceStdin:
+		return &ReaderSource{SourceName: "stdin", Reader: os.Stdin}, nil
+	default:
+		return nil, fmt.Errorf("unknown address source %q", config.Kind)
+	}
+}
+
+// S3Source reads ZIP archives and text files of addresses from S3
+type S3Source struct {
+	Config S3Config
+}
+
+func (source S3Source) Name() string {
+	locations := append(slices.Clone(source.Config.Keys), source.Config.Prefixes...)
+	if len(locations) == 1 {
+		return fmt.Sprintf("s3://%s/%s", source.Config.Bucket, locations[0])
+	}
+	return fmt.Sprintf("s3://%s/{%s}", source.Config.Bucket, strings.Join(locations, ","))
+}
+
+// Version identifies the ETags and version IDs of the configured objects
+func (source S3Source) Version(ctx context.Context) (string, error) {
+	return S3Version(ctx, source.Config)
+}
+
+func (source S3Source) Load(ctx context.Context, batchSize int, processor func([]LandAddress) error) error {
+	return LoadLandAddressesFromS3Batch(ctx, source.Config, batchSize, processor)
+}
+
+// PostgresSource reads the land table
+type PostgresSource struct {
+	Config Config
+}
+
+func (source PostgresSource) Name() string {
+	return fmt.Sprintf("postgres://%s:%d/%s", source.Config.Host, source.Config.Port, source.Config.DBName)
+}
+
+func (source PostgresSource) Load(ctx context.Context, batchSize int, processor func([]LandAddress) error) error {
+	db, err := Connect(source.Config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	return LoadLandAddressesBatch(ctx, db, batchSize, processor)
+}
+
+// FileSource reads a local directory of .txt files, a single .txt file or a ZIP archive
+type FileSource struct {
+	Path string
+}
+
+func (source FileSource) Name() string {
+	return source.Path
+}
+
+func (source FileSource) Load(ctx context.Context, batchSize int, processor func([]LandAddress) error) error {
+	return LoadLandAddressesFromFilesBatch(ctx, source.Path, batchSize, processor)
+}
+
+// ReaderSource reads address lines from a stream such as stdin. The stream
+// is consumed by the first Load, so later loads (reloads) fail with
+// ErrSourceConsumed instead of building an empty trie.
+type ReaderSource struct {
+	SourceName string
+	Reader     io.Reader
+
+	consumed atomic.Bool
+}
+
+func (source *ReaderSource) Name() string {
+	return source.SourceName
+}
+
+func (source *ReaderSource) Load(ctx context.Context, batchSize int, processor func([]LandAddress) error) error {
+	if !source.consumed.CompareAndSwap(false, true) {
+		return fmt.Errorf("failed to load %s: %w", source.SourceName, ErrSourceConsumed)
+	}
+	return LoadLandAddressesFromReaderBatch(ctx, source.Reader, source.SourceName, batchSize, processor)
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReaderSourceLoadsOnce(t *testing.T) {
+	source := &ReaderSource{SourceName: "stdin", Reader: strings.NewReader("서울특별시 강남구 역삼동 737\n")}
+
+	count := 0
+	processor := func(batch []LandAddress) error {
+		count += len(batch)
+		return nil
+	}
+	if err := source.Load(context.Background(), 10, processor); err != nil {
+		t.Fatalf("first Load() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("first Load() processed %d addresses, want 1", count)
+	}
+
+	// 두 번째 적재는 빈 트라이를 만들지 않고 실패
+	if err := source.Load(context.Background(), 10, processor); !errors.Is(err, ErrSourceConsumed) {
+		t.Errorf("second Load() error = %v, want ErrSourceConsumed", err)
+	}
+}
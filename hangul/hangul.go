@@ -0,0 +1,54 @@
+package hangul
+
+const (
+	syllableBase  = 0xAC00
+	syllableLast  = 0xD7A3
+	jungseongSize = 21
+	jongseongSize = 28
+	choseongSpan  = jungseongSize * jongseongSize
+)
+
+// choseongs는 초성 인덱스 순서의 호환용 자모
+var choseongs = []rune{
+	'ㄱ', 'ㄲ', 'ㄴ', 'ㄷ', 'ㄸ', 'ㄹ', 'ㅁ', 'ㅂ', 'ㅃ', 'ㅅ',
+	'ㅆ', 'ㅇ', 'ㅈ', 'ㅉ', 'ㅊ', 'ㅋ', 'ㅌ', 'ㅍ', 'ㅎ',
+}
+
+// IsSyllable reports whether r is a precomposed Hangul syllable (가-힣)
+func IsSyllable(r rune) bool {
+	return r >= syllableBase && r <= syllableLast
+}
+
+// IsChoseong reports whether r is a compatibility jamo that can start a syllable
+func IsChoseong(r rune) bool {
+	return choseongIndex(r) >= 0
+}
+
+// Choseong returns the initial consonant of a syllable as a compatibility jamo.
+// Runes that are not syllables are returned unchanged.
+func Choseong(r rune) rune {
+	if !IsSyllable(r) {
+		return r
+	}
+	return choseongs[(r-syllableBase)/choseongSpan]
+}
+
+// ChoseongRange returns the inclusive range of syllables starting with the
+// given initial consonant. ok is false when r is not an initial consonant.
+func ChoseongRange(r rune) (first, last rune, ok bool) {
+	index := choseongIndex(r)
+	if index < 0 {
+		return 0, 0, false
+	}
+	first = syllableBase + rune(index*choseongSpan)
+	return first, first + choseongSpan - 1, true
+}
+
+func choseongIndex(r rune) int {
+	for i, c := range choseongs {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
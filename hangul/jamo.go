@@ -0,0 +1,65 @@
+package hangul
+
+// jungseongs는 중성 인덱스 순서의 호환용 자모
+var jungseongs = []rune{
+	'ㅏ', 'ㅐ', 'ㅑ', 'ㅒ', 'ㅓ', 'ㅔ', 'ㅕ', 'ㅖ', 'ㅗ', 'ㅘ',
+	'ㅙ', 'ㅚ', 'ㅛ', 'ㅜ', 'ㅝ', 'ㅞ', 'ㅟ', 'ㅠ', 'ㅡ', 'ㅢ', 'ㅣ',
+}
+
+// jongseongs는 종성 인덱스 순서의 호환용 자모 (0은 받침 없음)
+var jongseongs = []rune{
+	0, 'ㄱ', 'ㄲ', 'ㄳ', 'ㄴ', 'ㄵ', 'ㄶ', 'ㄷ', 'ㄹ', 'ㄺ',
+	'ㄻ', 'ㄼ', 'ㄽ', 'ㄾ', 'ㄿ', 'ㅀ', 'ㅁ', 'ㅂ', 'ㅄ', 'ㅅ',
+	'ㅆ', 'ㅇ', 'ㅈ', 'ㅊ', 'ㅋ', 'ㅌ', 'ㅍ', 'ㅎ',
+}
+
+// compounds는 겹모음과 겹받침을 입력 순서대로 나눈 것
+var compounds = map[rune][]rune{
+	'ㅘ': {'ㅗ', 'ㅏ'}, 'ㅙ': {'ㅗ', 'ㅐ'}, 'ㅚ': {'ㅗ', 'ㅣ'},
+	'ㅝ': {'ㅜ', 'ㅓ'}, 'ㅞ': {'ㅜ', 'ㅔ'}, 'ㅟ': {'ㅜ', 'ㅣ'},
+	'ㅢ': {'ㅡ', 'ㅣ'},
+	'ㄳ': {'ㄱ', 'ㅅ'}, 'ㄵ': {'ㄴ', 'ㅈ'}, 'ㄶ': {'ㄴ', 'ㅎ'},
+	'ㄺ': {'ㄹ', 'ㄱ'}, 'ㄻ': {'ㄹ', 'ㅁ'}, 'ㄼ': {'ㄹ', 'ㅂ'},
+	'ㄽ': {'ㄹ', 'ㅅ'}, 'ㄾ': {'ㄹ', 'ㅌ'}, 'ㄿ': {'ㄹ', 'ㅍ'},
+	'ㅀ': {'ㄹ', 'ㅎ'}, 'ㅄ': {'ㅂ', 'ㅅ'},
+}
+
+// Decompose splits r into the compatibility jamo in the order they are typed,
+// so that a syllable still being composed is a prefix of the finished one
+// (e.g. 과 -> ㄱ ㅗ ㅏ, 값 -> ㄱ ㅏ ㅂ ㅅ). Other runes are returned as is.
+func Decompose(r rune) []rune {
+	if !IsSyllable(r) {
+		if parts, ok := compounds[r]; ok {
+			return append([]rune(nil), parts...)
+		}
+		return []rune{r}
+	}
+
+	offset := r - syllableBase
+	cho := choseongs[offset/choseongSpan]
+	jung := jungseongs[(offset%choseongSpan)/jongseongSize]
+	jong := jongseongs[offset%jongseongSize]
+
+	keys := []rune{cho}
+	keys = appendJamo(keys, jung)
+	if jong != 0 {
+		keys = appendJamo(keys, jong)
+	}
+	return keys
+}
+
+// DecomposeString decomposes every rune of s
+func DecomposeString(s string) []rune {
+	keys := make([]rune, 0, len(s))
+	for _, r := range s {
+		keys = append(keys, Decompose(r)...)
+	}
+	return keys
+}
+
+func appendJamo(keys []rune, jamo rune) []rune {
+	if parts, ok := compounds[jamo]; ok {
+		return append(keys, parts...)
+	}
+	return append(keys, jamo)
+}
@@ -0,0 +1,37 @@
+package hangul
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecompose(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want []rune
+	}{
+		{'가', []rune{'ㄱ', 'ㅏ'}},
+		{'강', []rune{'ㄱ', 'ㅏ', 'ㅇ'}},
+		{'과', []rune{'ㄱ', 'ㅗ', 'ㅏ'}},
+		{'값', []rune{'ㄱ', 'ㅏ', 'ㅂ', 'ㅅ'}},
+		{'뷁', []rune{'ㅂ', 'ㅜ', 'ㅔ', 'ㄹ', 'ㄱ'}},
+		{'ㄱ', []rune{'ㄱ'}},
+		{'ㅘ', []rune{'ㅗ', 'ㅏ'}},
+		{'ㄳ', []rune{'ㄱ', 'ㅅ'}},
+		{'1', []rune{'1'}},
+		{'a', []rune{'a'}},
+	}
+
+	for _, tt := range tests {
+		if got := Decompose(tt.r); !slices.Equal(got, tt.want) {
+			t.Errorf("Decompose(%q) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestDecomposeString(t *testing.T) {
+	want := []rune{'ㄱ', 'ㅏ', 'ㅇ', 'ㄴ', 'ㅏ', 'ㅁ', ' ', '1', '2'}
+	if got := DecomposeString("강남 12"); !slices.Equal(got, want) {
+		t.Errorf("DecomposeString() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package hangul
+
+import "strings"
+
+// keyboard는 두벌식 자판에서 영문 키에 대응하는 자모
+var keyboard = map[rune]rune{
+	'q': 'ㅂ', 'w': 'ㅈ', 'e': 'ㄷ', 'r': 'ㄱ', 't': 'ㅅ',
+	'y': 'ㅛ', 'u': 'ㅕ', 'i': 'ㅑ', 'o': 'ㅐ', 'p': 'ㅔ',
+	'a': 'ㅁ', 's': 'ㄴ', 'd': 'ㅇ', 'f': 'ㄹ', 'g': 'ㅎ',
+	'h': 'ㅗ', 'j': 'ㅓ', 'k': 'ㅏ', 'l': 'ㅣ',
+	'z': 'ㅋ', 'x': 'ㅌ', 'c': 'ㅊ', 'v': 'ㅍ', 'b': 'ㅠ',
+	'n': 'ㅜ', 'm': 'ㅡ',
+	'Q': 'ㅃ', 'W': 'ㅉ', 'E': 'ㄸ', 'R': 'ㄲ', 'T': 'ㅆ',
+	'O': 'ㅒ', 'P': 'ㅖ',
+}
+
+// FromKeyboard converts text typed on a QWERTY layout while the IME was in
+// English mode into the Hangul the user meant (e.g. tjdnf -> 서울).
+// ok is false when s has no Latin letters, contains Hangul already, does not
+// form a valid 두벌식 sequence (a vowel left without an initial consonant), or
+// composes no syllable at all: consonant-only input such as "test" is far more
+// likely to be meant as English than as a 초성 query.
+//
+// Input typed entirely in capitals is taken as Caps Lock and read in lower
+// case. Otherwise a capital is a Shift key, which gives ㅃㅉㄸㄲㅆㅒㅖ and is
+// ignored on the other keys.
+func FromKeyboard(s string) (string, bool) {
+	// Caps Lock이 켜져 있으면 모든 영문자가 대문자
+	if strings.ToUpper(s) == s {
+		s = strings.ToLower(s)
+	}
+
+	var builder strings.Builder
+	var composer composer
+	hasLetter := false
+
+	for _, r := range s {
+		jamo, ok := keyboard[r]
+		if !ok && r >= 'A' && r <= 'Z' {
+			// Shift를 눌러도 쌍자음이나 ㅒ, ㅖ가 없는 키는 소문자와 같음
+			jamo, ok = keyboard[r+('a'-'A')]
+		}
+
+		switch {
+		case ok:
+			hasLetter = true
+			if !composer.push(&builder, jamo) {
+				return "", false
+			}
+		case IsSyllable(r) || isJamo(r):
+			return "", false
+		default:
+			if !composer.flush(&builder) {
+				return "", false
+			}
+			builder.WriteRune(r)
+		}
+	}
+
+	if !hasLetter || !composer.flush(&builder) {
+		return "", false
+	}
+
+	// 완성된 글자가 하나도 없으면 한글로 입력하려던 것으로 보지 않음
+	converted := builder.String()
+	if !strings.ContainsFunc(converted, IsSyllable) {
+		return "", false
+	}
+	return converted, true
+}
+
+// composer assembles jamo keystrokes into syllables like a 두벌식 IME
+type composer struct {
+	cho, jung, jong rune
+}
+
+func (c *composer) push(builder *strings.Builder, jamo rune) bool {
+	if isVowel(jamo) {
+		return c.pushVowel(builder, jamo)
+	}
+	return c.pushConsonant(builder, jamo)
+}
+
+func (c *composer) pushConsonant(builder *strings.Builder, jamo rune) bool {
+	switch {
+	case c.jong != 0:
+		if combined, ok := combine(c.jong, jamo); ok && jongseongIndex(combined) > 0 {
+			c.jong = combined
+			return true
+		}
+	case c.cho != 0 && c.jung != 0:
+		if jongseongIndex(jamo) > 0 {
+			c.jong = jamo
+			return true
+		}
+	}
+
+	if !c.flush(builder) {
+		return false
+	}
+	c.cho = jamo
+	return true
+}
+
+func (c *composer) pushVowel(builder *strings.Builder, jamo rune) bool {
+	switch {
+	case c.jong != 0:
+		// 받침의 마지막 자음을 다음 글자의 초성으로 옮김 (값 + ㅣ -> 갑시)
+		next := c.jong
+		if parts, ok := compounds[c.jong]; ok {
+			c.jong = parts[0]
+			next = parts[1]
+		} else {
+			c.jong = 0
+		}
+		if !c.flush(builder) {
+			return false
+		}
+		c.cho = next
+		c.jung = jamo
+		return true
+	case c.cho != 0 && c.jung == 0:
+		c.jung = jamo
+		return true
+	case c.jung != 0:
+		if combined, ok := combine(c.jung, jamo); ok {
+			c.jung = combined
+			return true
+		}
+	}
+
+	if !c.flush(builder) {
+		return false
+	}
+	c.jung = jamo
+	return true
+}
+
+// flush writes the pending syllable. A lone vowel cannot be part of an address,
+// so it makes the sequence invalid; a lone consonant is kept as a 초성 query.
+func (c *composer) flush(builder *strings.Builder) bool {
+	defer func() { *c = composer{} }()
+
+	switch {
+	case c.cho != 0 && c.jung != 0:
+		builder.WriteRune(compose(c.cho, c.jung, c.jong))
+	case c.cho != 0:
+		builder.WriteRune(c.cho)
+	case c.jung != 0:
+		return false
+	}
+	return true
+}
+
+func compose(cho, jung, jong rune) rune {
+	return syllableBase +
+		rune(choseongIndex(cho)*choseongSpan) +
+		rune(indexOf(jungseongs, jung)*jongseongSize) +
+		rune(jongseongIndex(jong))
+}
+
+// combine returns the compound jamo typed as first followed by second
+func combine(first, second rune) (rune, bool) {
+	for compound, parts := range compounds {
+		if parts[0] == first && parts[1] == second {
+			return compound, true
+		}
+	}
+	return 0, false
+}
+
+func jongseongIndex(r rune) int {
+	if r == 0 {
+		return 0
+	}
+	return indexOf(jongseongs, r)
+}
+
+func isVowel(r rune) bool {
+	return indexOf(jungseongs, r) >= 0
+}
+
+func isJamo(r rune) bool {
+	return r >= 0x3131 && r <= 0x318E
+}
+
+func indexOf(table []rune, r rune) int {
+	for i, c := range table {
+		if c == r {
+			return i
+		}
+	}
+	return -1
+}
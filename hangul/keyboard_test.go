@@ -0,0 +1,43 @@
+package hangul
+
+import "testing"
+
+func TestFromKeyboard(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{"tjdnf", "서울", true},
+		{"rkdskarn", "강남구", true},
+		{"tjdnf rkdskarn dur", "서울 강남구 역", true},
+		{"dhkd", "왕", true},
+		{"rkqt", "값", true},
+		{"rkqtl", "갑시", true},
+		// Shift로 입력한 쌍자음과 Caps Lock으로 입력한 대문자
+		{"Rkr", "깍", true},
+		{"dhRk", "오까", true},
+		{"tJdnf", "서울", true},
+		{"TJDNF", "서울", true},
+		{"RKDSKARN DUR 12", "강남구 역 12", true},
+		{"dur 12-3", "역 12-3", true},
+		{"tjdnfe", "서울ㄷ", true},
+
+		// 완성된 글자가 없으면 영어로 봄
+		{"test", "", false},
+		{"rt", "", false},
+		// 초성 없는 모음, 이미 한글이 섞인 입력, 영문자가 없는 입력
+		{"k", "", false},
+		{"서울", "", false},
+		{"ㅅtj", "", false},
+		{"123", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := FromKeyboard(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("FromKeyboard(%q) = %q, %v, want %q, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+-- 주소 적재 시 keyset 페이지네이션용 인덱스
+-- land.sql은 자동 생성된 정의이므로 변경은 이 파일로 적용
+create index if not exists land_full_code_id_idx
+    on land ((coalesce(full_code, '')), id)
+    where address is not null and address <> '';
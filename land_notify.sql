@@ -0,0 +1,41 @@
+-- land 테이블 변경을 LISTEN/NOTIFY로 알리는 트리거
+-- 채널 이름은 트리거 인자로 받으며, 서버의 LAND_NOTIFY_CHANNEL과 같아야 함
+create or replace function land_notify() returns trigger as
+$$
+declare
+    changed land;
+begin
+    if tg_op = 'DELETE' then
+        changed := old;
+    else
+        changed := new;
+    end if;
+
+    -- 주소나 위치가 바뀌지 않은 수정은 알리지 않음
+    if tg_op = 'UPDATE'
+        and new.address is not distinct from old.address
+        and new.road_address is not distinct from old.road_address
+        and new.full_code is not distinct from old.full_code
+        and new.center_point is not distinct from old.center_point then
+        return null;
+    end if;
+
+    perform pg_notify(tg_argv[0], json_build_object(
+            'op', tg_op,
+            'unique_no', changed.unique_no,
+            'address', changed.address,
+            'road_address', changed.road_address,
+            'full_code', changed.full_code,
+            'lat', st_y(changed.center_point),
+            'lng', st_x(changed.center_point)
+        )::text);
+
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger land_notify
+    after insert or update or delete
+    on land
+    for each row
+execute function land_notify('land_changes');
@@ -0,0 +1,4 @@
+-- land 테이블에 도로명주소 컬럼 추가
+-- land.sql은 자동 생성된 정의이므로 변경은 이 파일로 적용
+alter table land
+    add column if not exists road_address varchar(128);
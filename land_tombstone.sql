@@ -0,0 +1,29 @@
+-- 지워진 land 행을 증분 동기화가 읽을 수 있도록 남기는 테이블과 트리거
+-- 서버는 가장 오래 실행 중인 인스턴스가 시작된 이후의 행만 읽으므로 그 이전 행은 지워도 됨
+create table if not exists land_tombstone
+(
+    id         bigint primary key,
+    unique_no  varchar(20) not null,
+    deleted_at timestamp   not null default current_timestamp
+);
+
+-- 증분 동기화(deleted_at 워터마크)용 인덱스
+create index if not exists land_tombstone_deleted_at_id_idx
+    on land_tombstone (deleted_at, id);
+
+create or replace function land_tombstone() returns trigger as
+$$
+begin
+    insert into land_tombstone (id, unique_no)
+    values (old.id, old.unique_no)
+    on conflict (id) do update set unique_no  = excluded.unique_no,
+                                   deleted_at = excluded.deleted_at;
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger land_tombstone
+    after delete
+    on land
+    for each row
+execute function land_tombstone();
@@ -0,0 +1,19 @@
+-- 증분 동기화가 읽는 updated_at 워터마크용 인덱스와 수정 시각 트리거
+-- land.sql은 자동 생성된 정의이므로 변경은 이 파일로 적용
+create index if not exists land_updated_at_id_idx
+    on land (updated_at, id);
+
+-- 행이 수정될 때마다 updated_at 갱신
+create or replace function land_touch_updated_at() returns trigger as
+$$
+begin
+    new.updated_at := current_timestamp;
+    return new;
+end;
+$$ language plpgsql;
+
+create trigger land_touch_updated_at
+    before update
+    on land
+    for each row
+execute function land_touch_updated_at();
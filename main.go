@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"gin-project/database"
 	"gin-project/service"
 	"log"
@@ -24,10 +27,63 @@ func main() {
 	batchSize := getBatchSize("BATCH_SIZE", database.DefaultBatchSize)
 	log.Printf("Using batch size: %d", batchSize)
 
-	// 트라이 서비스 초기화 (S3에서 데이터 로드)
+	// 주소 데이터 소스 설정 (ADDRESS_SOURCE=s3|postgres|files|stdin)
+	source, err := database.SourceFromEnv(getEnv("ADDRESS_SOURCE", database.SourceS3), getEnv("ADDRESS_PATH", ""))
+	if err != nil {
+		log.Fatalf("Failed to configure address source: %v", err)
+	}
+
+	// 트라이 서비스 초기화 (스냅샷이 있으면 스냅샷, 없으면 소스에서 데이터 로드)
 	trieService := service.GetTrieService()
-	if err := trieService.InitializeFromS3(batchSize); err != nil {
-		log.Fatalf("Failed to initialize trie service from S3: %v", err)
+	trieService.SetFuzzyMaxDistance(getNonNegativeInt("FUZZY_MAX_DISTANCE", service.DefaultFuzzyMaxDistance))
+	if snapshotPath := getEnv("SNAPSHOT_PATH", ""); snapshotPath != "" {
+		if err := trieService.InitializeFromSnapshot(snapshotPath); err != nil {
+			log.Fatalf("Failed to initialize trie service from snapshot: %v", err)
+		}
+	} else if err := trieService.Initialize(context.Background(), source, batchSize); err != nil {
+		log.Fatalf("Failed to initialize trie service from %s: %v", source.Name(), err)
+	}
+
+	// 주기적 재적재 설정 (예: RELOAD_INTERVAL=6h), 표준 입력은 한 번만 읽을 수 있으므로 제외
+	if interval := getDuration("RELOAD_INTERVAL", 0); interval > 0 && getEnv("ADDRESS_SOURCE", database.SourceS3) == database.SourceStdin {
+		log.Printf("Periodic reload is not supported for the %s source, ignoring RELOAD_INTERVAL", database.SourceStdin)
+	} else if interval > 0 {
+		log.Printf("Reloading trie from %s every %s", source.Name(), interval)
+		stopReload := trieService.StartPeriodicReload(interval, source, batchSize)
+		defer stopReload()
+	}
+
+	// land 테이블 변경 반영 설정
+	// 증분 동기화 (예: DELTA_SYNC_INTERVAL=1m), LISTEN/NOTIFY (예: LAND_NOTIFY_CHANNEL=land_changes)
+	syncInterval := getDuration("DELTA_SYNC_INTERVAL", 0)
+	notifyChannel := getEnv("LAND_NOTIFY_CHANNEL", "")
+	if syncInterval > 0 || notifyChannel != "" {
+		dbConfig, err := database.ConfigFromEnv()
+		if err != nil {
+			log.Fatalf("Failed to configure database for land changes: %v", err)
+		}
+		db, err := database.Connect(dbConfig)
+		if err != nil {
+			log.Fatalf("Failed to connect to database for land changes: %v", err)
+		}
+		defer db.Close()
+
+		if syncInterval > 0 {
+			stopSync, err := trieService.StartDeltaSync(db, syncInterval, batchSize)
+			if err != nil {
+				log.Fatalf("Failed to start delta sync: %v", err)
+			}
+			defer stopSync()
+			log.Printf("Syncing land changes every %s", syncInterval)
+		}
+
+		if notifyChannel != "" {
+			stopListener, err := trieService.StartListener(db, dbConfig, notifyChannel, batchSize)
+			if err != nil {
+				log.Fatalf("Failed to start land listener: %v", err)
+			}
+			defer stopListener()
+		}
 	}
 
 	// Gin 라우터 생성
@@ -67,14 +123,110 @@ func main() {
 			return
 		}
 
-		results := trieService.Search(query)
+		limit := service.DefaultSearchLimit
+		if value := c.Query("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Query parameter 'limit' must be a positive integer",
+				})
+				return
+			}
+			limit = parsed
+		}
+
+		// type: jibun, road 또는 all (기본값 all)
+		page, err := trieService.Search(query, c.DefaultQuery("type", service.AddressTypeAll), limit, c.Query("cursor"))
+		if errors.Is(err, service.ErrInvalidAddressType) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Query parameter 'type' must be one of jibun, road or all",
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Query parameter 'cursor' is invalid",
+			})
+			return
+		}
+
+		var nextCursor, convertedFrom any
+		if page.NextCursor != "" {
+			nextCursor = page.NextCursor
+		}
+		if page.ConvertedFrom != "" {
+			convertedFrom = page.ConvertedFrom
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"data": gin.H{
-				"results": results,
+				"query":          page.Query,
+				"converted_from": convertedFrom,
+				"results":        page.Results,
+				"next_cursor":    nextCursor,
 			},
 		})
 	})
 
+	// 관리자 재적재 엔드포인트 (ADMIN_TOKEN이 설정된 경우에만 활성화)
+	if adminToken := getEnv("ADMIN_TOKEN", ""); adminToken != "" {
+		r.POST("/api/v1/admin/reload", func(c *gin.Context) {
+			if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(adminToken)) != 1 {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Invalid admin token",
+				})
+				return
+			}
+
+			// 새 트라이는 백그라운드에서 만들고 완성되면 교체
+			started := make(chan error, 1)
+			go func() {
+				err := trieService.Reload(context.Background(), source, batchSize)
+				started <- err
+				if err != nil && !errors.Is(err, service.ErrReloadInProgress) && !errors.Is(err, service.ErrSourceUnchanged) {
+					log.Printf("Reload failed: %v", err)
+				}
+			}()
+
+			select {
+			case err := <-started:
+				if errors.Is(err, service.ErrReloadInProgress) {
+					c.JSON(http.StatusConflict, gin.H{
+						"error": "Reload already in progress",
+					})
+					return
+				}
+				if errors.Is(err, database.ErrSourceConsumed) {
+					c.JSON(http.StatusConflict, gin.H{
+						"error": "Address source cannot be reloaded",
+					})
+					return
+				}
+				if errors.Is(err, service.ErrSourceUnchanged) {
+					c.JSON(http.StatusOK, gin.H{
+						"status": "unchanged",
+					})
+					return
+				}
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{
+						"error": "Reload failed",
+					})
+					return
+				}
+				c.JSON(http.StatusOK, gin.H{
+					"status": "reloaded",
+				})
+			case <-time.After(time.Second):
+				c.JSON(http.StatusAccepted, gin.H{
+					"status": "reloading",
+				})
+			}
+		})
+	} else {
+		log.Println("ADMIN_TOKEN not set, admin endpoints are disabled")
+	}
+
 	log.Println("Starting server on :8080")
 	r.Run(":8080")
 }
@@ -95,6 +247,24 @@ func getBatchSize(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getNonNegativeInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if number, err := strconv.Atoi(value); err == nil && number >= 0 {
+			return number
+		}
+	}
+	return defaultValue
+}
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
 func getPort(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if port, err := strconv.Atoi(value); err == nil && port > 0 {
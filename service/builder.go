@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gin-project/database"
+	"gin-project/trie"
+	"log"
+)
+
+// Build loads every address from source into a new index
+func Build(ctx context.Context, source database.AddressSource, batchSize int) (*Index, error) {
+	index := NewIndex()
+
+	// 배치로 주소 로드 및 처리
+	if err := source.Load(ctx, batchSize, insertProcessor(index)); err != nil {
+		return nil, fmt.Errorf("failed to load addresses from %s in batches: %w", source.Name(), err)
+	}
+
+	log.Printf("Successfully completed loading all addresses from %s into trie", source.Name())
+	return index, nil
+}
+
+// insertProcessor returns a batch processor that inserts every address into
+// the jibun trie and its road-name address, when present, into the road trie
+func insertProcessor(index *Index) func([]database.LandAddress) error {
+	return func(addresses []database.LandAddress) error {
+		for i, address := range addresses {
+			// 안전장치: 빈 문자열 체크
+			if len(address.Address) == 0 {
+				log.Printf("ERROR: Empty address found in batch at index %d", i)
+				continue
+			}
+
+			// 디버그: 문제가 될 수 있는 주소 로깅
+			if len(address.Address) < 2 {
+				continue
+			}
+
+			index.Jibun.Insert(address.Address, address.Weight, newPayload(address, address.RoadAddress))
+
+			// 도로명주소는 같은 필지로 도로명 트라이에 추가
+			if len(address.RoadAddress) >= 2 {
+				index.Road.Insert(address.RoadAddress, address.Weight, newPayload(address, address.Address))
+			}
+		}
+		return nil
+	}
+}
+
+// newPayload returns the trie payload for a record with the other form of its
+// address as counterpart, or nil when it has neither parcel data nor counterpart
+func newPayload(address database.LandAddress, counterpart string) *trie.Payload {
+	if address.UniqueNo == "" && address.FullCode == "" && address.Lat == 0 && address.Lng == 0 && counterpart == "" {
+		return nil
+	}
+	return &trie.Payload{
+		UniqueNo:    address.UniqueNo,
+		FullCode:    address.FullCode,
+		Lat:         address.Lat,
+		Lng:         address.Lng,
+		Counterpart: counterpart,
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+const cursorPrefix = "o:"
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different search.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// encodeCursor builds an opaque cursor that resumes the search identified by
+// key after offset results.
+func encodeCursor(offset int, key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset) + ":" + key))
+}
+
+// decodeCursor returns the number of results already served for the cursor,
+// rejecting cursors issued for another search than key. An empty cursor means
+// the first page.
+func decodeCursor(cursor string, key string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	value, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+
+	// 다른 질의나 주소 유형의 커서로 이어서 조회하지 않도록 확인
+	value, cursorKey, ok := strings.Cut(value, ":")
+	if !ok || cursorKey != key {
+		return 0, ErrInvalidCursor
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return offset, nil
+}
+
+// cursorKey identifies a search by its query text and address type
+func cursorKey(query string, addressType string) string {
+	if addressType == "" {
+		addressType = AddressTypeAll
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(query))
+	hash.Write([]byte{0})
+	hash.Write([]byte(addressType))
+	return strconv.FormatUint(hash.Sum64(), 36)
+}
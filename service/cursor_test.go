@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"gin-project/database"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := cursorKey("역삼동", AddressTypeJibun)
+	for _, offset := range []int{0, 1, 50, MaxSearchWindow} {
+		got, err := decodeCursor(encodeCursor(offset, key), key)
+		if err != nil || got != offset {
+			t.Errorf("decodeCursor(encodeCursor(%d)) = %d, %v", offset, got, err)
+		}
+	}
+
+	if offset, err := decodeCursor("", key); offset != 0 || err != nil {
+		t.Errorf("decodeCursor(\"\") = %d, %v, want the first page", offset, err)
+	}
+
+	for name, cursor := range map[string]string{
+		"other query":    encodeCursor(10, cursorKey("삼성동", AddressTypeJibun)),
+		"other type":     encodeCursor(10, cursorKey("역삼동", AddressTypeRoad)),
+		"negative":       encodeCursor(-1, key),
+		"not base64":     "!!!",
+		"missing prefix": "MTA6YWJj",
+	} {
+		if _, err := decodeCursor(cursor, key); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: decodeCursor() error = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+func TestSearchPaging(t *testing.T) {
+	addresses := make([]database.LandAddress, 0, MaxSearchWindow+100)
+	for i := range MaxSearchWindow + 100 {
+		addresses = append(addresses, database.LandAddress{Address: fmt.Sprintf("서울특별시 강남구 역삼동 %d", i+1), UniqueNo: fmt.Sprint(i)})
+	}
+	ts := newTestService(addresses...)
+
+	// 다음 커서를 따라가면 창 끝까지 중복 없이 한 번씩
+	seen := make(map[string]bool)
+	cursor, pages := "", 0
+	for {
+		page, err := ts.Search("역삼동", AddressTypeJibun, MaxSearchLimit, cursor)
+		if err != nil {
+			t.Fatalf("Search() page %d error = %v", pages, err)
+		}
+		pages++
+		for _, result := range page.Results {
+			if seen[result.Address] {
+				t.Fatalf("Search() page %d repeats %q", pages, result.Address)
+			}
+			seen[result.Address] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != MaxSearchWindow || pages != MaxSearchWindow/MaxSearchLimit {
+		t.Errorf("paged through %d results in %d pages, want %d in %d", len(seen), pages, MaxSearchWindow, MaxSearchWindow/MaxSearchLimit)
+	}
+
+	// 커서는 발급한 질의와 주소 유형에만 쓸 수 있음
+	first, err := ts.Search("역삼동", AddressTypeJibun, 10, "")
+	if err != nil || first.NextCursor == "" {
+		t.Fatalf("Search() = %+v, %v, want a next cursor", first, err)
+	}
+	if _, err := ts.Search("삼성동", AddressTypeJibun, 10, first.NextCursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Search() with another query's cursor error = %v, want ErrInvalidCursor", err)
+	}
+	if _, err := ts.Search("역삼동", AddressTypeAll, 10, first.NextCursor); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Search() with another type's cursor error = %v, want ErrInvalidCursor", err)
+	}
+
+	// 창 끝의 커서는 거부하고, 창 끝에 걸친 페이지는 남은 만큼만 반환
+	key := cursorKey("역삼동", AddressTypeJibun)
+	if _, err := ts.Search("역삼동", AddressTypeJibun, 10, encodeCursor(MaxSearchWindow, key)); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Search() at the window end error = %v, want ErrInvalidCursor", err)
+	}
+	last, err := ts.Search("역삼동", AddressTypeJibun, 10, encodeCursor(MaxSearchWindow-3, key))
+	if err != nil || len(last.Results) != 3 || last.NextCursor != "" {
+		t.Errorf("Search() before the window end = %d results, next cursor %q, %v, want 3 and none", len(last.Results), last.NextCursor, err)
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gin-project/database"
+	"gin-project/trie"
+	"log"
+	"time"
+)
+
+// SyncLookback is how far behind the watermark each delta sync starts reading.
+// updated_at is taken when a transaction starts, so a row committed late can
+// carry a timestamp older than changes already seen; re-reading a short window
+// catches it, and applying a change twice is harmless.
+const SyncLookback = time.Minute
+
+// ErrNoParcelIndex is returned when changes cannot be applied because the live
+// trie was loaded from a source without parcel unique_no
+var ErrNoParcelIndex = errors.New("live trie has no parcel index")
+
+// ApplyChanges applies changed land rows to the live tries: new parcels are
+// inserted, parcels whose address changed are moved and parcels without an
+// address are removed, in the jibun and road-name tries alike. It returns the
+// number of changes applied.
+func (ts *TrieService) ApplyChanges(changes []database.LandChange) int {
+	ts.applyMu.Lock()
+	defer ts.applyMu.Unlock()
+
+	index := ts.index.Load()
+
+	applied := 0
+	for _, change := range changes {
+		jibun := applyChange(index.Jibun, change.UniqueNo, change.Address, change.Deleted,
+			change.Weight, newPayload(change.LandAddress, change.RoadAddress))
+
+		// 도로명주소만 지워진 필지는 도로명 트라이에서만 삭제
+		road := applyChange(index.Road, change.UniqueNo, change.RoadAddress, change.Deleted || change.RoadAddress == "",
+			change.Weight, newPayload(change.LandAddress, change.Address))
+
+		if jibun || road {
+			applied++
+		}
+	}
+	return applied
+}
+
+// syncWatermark returns the current land table watermark when a delta sync is
+// running, or nil. A reload takes it before building so the sync replays only
+// the changes made since, not everything since the sync started.
+func (ts *TrieService) syncWatermark(ctx context.Context) *database.Watermark {
+	db := ts.syncDB.Load()
+	if db == nil {
+		return nil
+	}
+
+	watermark, err := database.CurrentWatermark(ctx, db)
+	if err != nil {
+		log.Printf("Failed to read land watermark before reload, delta sync will replay from its start: %v", err)
+		return nil
+	}
+	return &watermark
+}
+
+// applyChange moves the parcel uniqueNo to address in nodes, or removes it when deleted
+func applyChange(nodes *trie.NodeManager, uniqueNo string, address string, deleted bool, weight float32, payload *trie.Payload) bool {
+	if deleted {
+		return nodes.DeleteParcel(uniqueNo)
+	}
+
+	// 안전장치: 너무 짧은 주소는 적재 때와 마찬가지로 건너뜀
+	if len(address) < 2 {
+		return false
+	}
+
+	// 이전 주소 삭제와 새 주소 추가를 한 번에 적용해 검색에서 필지가 사라지지 않게 함
+	nodes.Upsert(address, weight, payload)
+	return true
+}
+
+// deltaSync tracks how far the live trie has caught up with the land table
+type deltaSync struct {
+	ts        *TrieService
+	db        *sql.DB
+	batchSize int
+	// start is the watermark the sync began from; an index swapped in by a
+	// reload is caught up again from there unless it records its own
+	start     database.Watermark
+	watermark database.Watermark
+	index     *Index
+}
+
+// StartDeltaSync applies land rows changed since the newest row present now to
+// the live trie every interval, until stop is called. Rows removed from the
+// table are read from land_tombstone, which must exist (see land_tombstone.sql).
+// The live trie must have been loaded with parcel unique_no, since changes find
+// the address to replace through them.
+func (ts *TrieService) StartDeltaSync(db *sql.DB, interval time.Duration, batchSize int) (stop func(), err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ds, err := ts.newDeltaSync(ctx, db, batchSize)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ds.run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					log.Printf("Delta sync failed: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cancel, nil
+}
+
+// newDeltaSync starts tracking the live trie from the newest row in the land table
+func (ts *TrieService) newDeltaSync(ctx context.Context, db *sql.DB, batchSize int) (*deltaSync, error) {
+	// unique_no 없이 적재된 트라이는 바뀐 필지의 이전 주소를 찾을 수 없음
+	if ts.index.Load().Jibun.ParcelCount() == 0 {
+		return nil, ErrNoParcelIndex
+	}
+
+	if err := database.CheckLandTombstones(ctx, db); err != nil {
+		return nil, err
+	}
+
+	watermark, err := database.CurrentWatermark(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	ts.syncDB.Store(db)
+
+	return &deltaSync{
+		ts:        ts,
+		db:        db,
+		batchSize: batchSize,
+		start:     watermark,
+		watermark: watermark,
+		index:     ts.index.Load(),
+	}, nil
+}
+
+// stale reports whether a reload swapped in an index this sync has not caught up
+func (ds *deltaSync) stale() bool {
+	return ds.ts.index.Load() != ds.index
+}
+
+// run applies the changes after the watermark to the live trie
+func (ds *deltaSync) run(ctx context.Context) error {
+	// 재적재 중에는 교체될 트라이에 적용하지 않도록 건너뜀
+	if ds.ts.reloading.Load() {
+		return nil
+	}
+
+	// 재적재로 트라이가 바뀌었으면 적재 직전 워터마크부터, 모르면 처음 워터마크부터 다시 적용
+	if ds.stale() {
+		index := ds.ts.index.Load()
+		if index.Jibun.ParcelCount() == 0 {
+			return ErrNoParcelIndex
+		}
+		ds.index = index
+		if index.since != nil {
+			log.Printf("Trie was reloaded, replaying land changes since %s", index.since.UpdatedAt.Format(time.RFC3339))
+			ds.watermark = *index.since
+		} else {
+			log.Println("Trie was reloaded, replaying land changes from the start of the delta sync")
+			ds.watermark = ds.start
+		}
+	}
+
+	since := ds.watermark
+	since.UpdatedAt = since.UpdatedAt.Add(-SyncLookback)
+	since.ID = 0
+
+	applied := 0
+	watermark, err := database.LoadLandChanges(ctx, ds.db, since, ds.batchSize, func(changes []database.LandChange) error {
+		applied += ds.ts.ApplyChanges(changes)
+		return nil
+	})
+	if watermark.UpdatedAt.After(ds.watermark.UpdatedAt) {
+		ds.watermark = watermark
+	}
+	if err != nil {
+		return err
+	}
+
+	if applied > 0 {
+		log.Printf("Delta sync applied %d land changes", applied)
+	}
+	return nil
+}
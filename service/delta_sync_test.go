@@ -0,0 +1,96 @@
+package service
+
+import (
+	"gin-project/database"
+	"gin-project/trie"
+	"testing"
+)
+
+func newTestService(addresses ...database.LandAddress) *TrieService {
+	ts := &TrieService{}
+	ts.fuzzyMaxDistance.Store(DefaultFuzzyMaxDistance)
+	index := NewIndex()
+	for _, address := range addresses {
+		index.Jibun.Insert(address.Address, address.Weight, newPayload(address, address.RoadAddress))
+		if address.RoadAddress != "" {
+			index.Road.Insert(address.RoadAddress, address.Weight, newPayload(address, address.Address))
+		}
+	}
+	ts.index.Store(index)
+	return ts
+}
+
+// indexed returns the address and counterpart indexed for uniqueNo in the trie of addressType
+func indexed(t *testing.T, ts *TrieService, addressType, uniqueNo string) (string, string) {
+	t.Helper()
+
+	nodes := ts.index.Load().Jibun
+	if addressType == AddressTypeRoad {
+		nodes = ts.index.Load().Road
+	}
+	address, ok := nodes.Parcel(uniqueNo)
+	if !ok {
+		return "", ""
+	}
+	results := nodes.Search(address, 1)
+	if len(results) != 1 || results[0].Address != address || results[0].Payload == nil {
+		t.Fatalf("%s trie does not find %q: %+v", addressType, address, results)
+	}
+	return address, results[0].Payload.Counterpart
+}
+
+func TestApplyChanges(t *testing.T) {
+	const (
+		jibun    = "서울특별시 강남구 역삼동 737"
+		moved    = "서울특별시 강남구 역삼동 738"
+		road     = "서울특별시 강남구 테헤란로 152"
+		newRoad  = "서울특별시 강남구 테헤란로 154"
+		uniqueNo = "1168010100107370000"
+	)
+
+	tests := []struct {
+		name                            string
+		change                          database.LandChange
+		wantJibun, wantJibunCounterpart string
+		wantRoad, wantRoadCounterpart   string
+	}{
+		{
+			name: "both moved",
+			change: database.LandChange{
+				LandAddress: database.LandAddress{UniqueNo: uniqueNo, Address: moved, RoadAddress: newRoad},
+			},
+			wantJibun: moved, wantJibunCounterpart: newRoad,
+			wantRoad: newRoad, wantRoadCounterpart: moved,
+		},
+		{
+			name: "road address cleared",
+			change: database.LandChange{
+				LandAddress: database.LandAddress{UniqueNo: uniqueNo, Address: jibun},
+			},
+			wantJibun: jibun,
+		},
+		{
+			name:   "deleted without road address",
+			change: database.LandChange{LandAddress: database.LandAddress{UniqueNo: uniqueNo}, Deleted: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(database.LandAddress{UniqueNo: uniqueNo, Address: jibun, RoadAddress: road})
+			ts.ApplyChanges([]database.LandChange{tt.change})
+
+			if address, counterpart := indexed(t, ts, AddressTypeJibun, uniqueNo); address != tt.wantJibun || counterpart != tt.wantJibunCounterpart {
+				t.Errorf("jibun = %q (%q), want %q (%q)", address, counterpart, tt.wantJibun, tt.wantJibunCounterpart)
+			}
+			if address, counterpart := indexed(t, ts, AddressTypeRoad, uniqueNo); address != tt.wantRoad || counterpart != tt.wantRoadCounterpart {
+				t.Errorf("road = %q (%q), want %q (%q)", address, counterpart, tt.wantRoad, tt.wantRoadCounterpart)
+			}
+			for _, nodes := range []*trie.NodeManager{ts.index.Load().Jibun, ts.index.Load().Road} {
+				if err := nodes.Validate(); err != nil {
+					t.Error(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"gin-project/database"
+	"gin-project/trie"
+)
+
+// Address types a search can be restricted to
+const (
+	AddressTypeJibun = "jibun"
+	AddressTypeRoad  = "road"
+	AddressTypeAll   = "all"
+)
+
+// ErrInvalidAddressType is returned when a search asks for an unknown address type
+var ErrInvalidAddressType = errors.New("invalid address type")
+
+// Index holds the tries searched by the service: jibun addresses and the
+// road-name addresses of the same parcels. Each payload carries the other
+// form of the address as its counterpart.
+type Index struct {
+	Jibun *trie.NodeManager
+	Road  *trie.NodeManager
+
+	// since is the land table watermark taken just before the index was
+	// built, nil when unknown. Delta sync replays the changes after it once
+	// the index is swapped in.
+	since *database.Watermark
+}
+
+// NewIndex returns an index with empty tries
+func NewIndex() *Index {
+	return &Index{Jibun: trie.CreateNodes(), Road: trie.CreateNodes()}
+}
+
+// typedTrie is a trie labelled with the address type it holds
+type typedTrie struct {
+	addressType string
+	nodes       *trie.NodeManager
+}
+
+// tries returns the tries searched for the given address type, jibun first
+func (index *Index) tries(addressType string) ([]typedTrie, error) {
+	switch addressType {
+	case AddressTypeJibun:
+		return []typedTrie{{AddressTypeJibun, index.Jibun}}, nil
+	case AddressTypeRoad:
+		return []typedTrie{{AddressTypeRoad, index.Road}}, nil
+	case AddressTypeAll, "":
+		return []typedTrie{{AddressTypeJibun, index.Jibun}, {AddressTypeRoad, index.Road}}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddressType, addressType)
+	}
+}
+
+// SaveSnapshot writes the jibun and road-name tries to one snapshot file at path
+func (index *Index) SaveSnapshot(path string) error {
+	return trie.SaveSnapshots(path, index.Jibun, index.Road)
+}
+
+// LoadSnapshot reads an index written by SaveSnapshot
+func LoadSnapshot(path string) (*Index, error) {
+	tries, err := trie.LoadSnapshots(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tries) != 2 {
+		return nil, fmt.Errorf("snapshot holds %d tries, want jibun and road-name", len(tries))
+	}
+	return &Index{Jibun: tries[0], Road: tries[1]}, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gin-project/database"
+	"log"
+)
+
+// StartListener applies the changes published on channel by the land_notify
+// trigger to the live trie as they arrive, until stop is called. After a lost
+// connection is re-established, or when a reload swapped in a new trie, the
+// land table is delta synced so nothing published in between is missed.
+func (ts *TrieService) StartListener(db *sql.DB, dbConfig database.Config, channel string, batchSize int) (stop func(), err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ds, err := ts.newDeltaSync(ctx, db, batchSize)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	listener, err := database.NewLandListener(dbConfig, channel)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	catchUp := func() {
+		if err := ds.run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Delta sync after land listener gap failed: %v", err)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer listener.Close()
+
+		err := listener.Run(ctx, func(change database.LandChange) {
+			// 재적재로 트라이가 바뀌었으면 그동안의 변경부터 다시 적용
+			if ds.stale() {
+				catchUp()
+			}
+			ts.ApplyChanges([]database.LandChange{change})
+		}, catchUp)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("Land listener stopped: %v", err)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
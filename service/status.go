@@ -0,0 +1,75 @@
+package service
+
+import (
+	"gin-project/trie"
+	"log"
+)
+
+// PrintTrieStatus logs the shape of the jibun and road-name tries: first level
+// children and jump references. It reads the nodes directly, so call it before
+// the index is published or while nothing else modifies it.
+func PrintTrieStatus(index *Index) {
+	printTrieStatus(AddressTypeJibun, index.Jibun)
+	printTrieStatus(AddressTypeRoad, index.Road)
+}
+
+func printTrieStatus(addressType string, nodes *trie.NodeManager) {
+	log.Printf("=== Trie Status (%s) ===", addressType)
+
+	// MainNode 상태
+	mainNodeChildrenCount := len(nodes.MainNode.Children)
+	log.Printf("MainNode - Children count: %d", mainNodeChildrenCount)
+
+	// MainNode의 첫 번째 레벨 자식들 일부 출력
+	if mainNodeChildrenCount > 0 {
+		log.Printf("MainNode - First level children (first 10):")
+		for i, child := range nodes.MainNode.Children {
+			if i >= 10 {
+				log.Printf("  ... and %d more children", mainNodeChildrenCount-10)
+				break
+			}
+			log.Printf("  [%d] '%c' (children: %d, isEnd: %t)", i, child.Value, len(child.Children), child.IsEnd)
+		}
+	}
+
+	// SubNodes 상태
+	subNodesCount := len(nodes.SubNodes)
+	log.Printf("SubNodes count: %d", subNodesCount)
+
+	for i, subNode := range nodes.SubNodes {
+		refCount := len(subNode.Ref)
+		log.Printf("SubNode[%d] - References count: %d", i, refCount)
+
+		// 각 SubNode의 참조들 일부 출력
+		if refCount > 0 {
+			log.Printf("  SubNode[%d] references (first 5):", i)
+			for j, ref := range subNode.Ref {
+				if j >= 5 {
+					log.Printf("    ... and %d more references", refCount-5)
+					break
+				}
+				// 참조된 노드의 값과 부모 경로 출력
+				path := getNodePath(ref)
+				log.Printf("    [%d] Node path: '%s' (isEnd: %t)", j, path, ref.IsEnd)
+			}
+		}
+	}
+
+	log.Println("=== End Trie Status ===")
+}
+
+// getNodePath returns the path from root to the given node
+func getNodePath(node *trie.FullNode) string {
+	if node == nil {
+		return ""
+	}
+
+	path := ""
+	current := node
+	for current != nil && current.Parent != nil {
+		path = string(current.Value) + path
+		current = current.Parent
+	}
+
+	return path
+}
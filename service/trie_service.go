@@ -1,15 +1,61 @@
 package service
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"gin-project/database"
+	"gin-project/hangul"
 	"gin-project/trie"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+const (
+	// DefaultSearchLimit is the number of results returned when no limit is given
+	DefaultSearchLimit = 5
+	// MaxSearchLimit is the largest page size a client may request
+	MaxSearchLimit = 50
+	// MaxSearchWindow bounds how deep a client may page into the results
+	MaxSearchWindow = 500
+	// DefaultFuzzyMaxDistance is the jamo edit distance allowed by the typo fallback
+	DefaultFuzzyMaxDistance = 2
+)
+
+var (
+	// ErrReloadInProgress is returned when a reload is requested while another one is running
+	ErrReloadInProgress = errors.New("reload already in progress")
+	// ErrSourceUnchanged is returned when a reload finds the source unchanged since the last load
+	ErrSourceUnchanged = errors.New("address source unchanged")
+	// ErrEmptyIndex is returned instead of publishing an index without any address
+	ErrEmptyIndex = errors.New("index holds no addresses")
+)
+
+// TrieService serves searches from the current index of jibun and road-name
+// address tries.
+//
+// Concurrency model: a full reload builds a new index on the side, where no
+// search can see it, and swaps it in atomically once it is complete, so the
+// previous index keeps serving traffic until then. Each search loads the
+// current index once and uses it to the end, even if a reload swaps it out
+// meanwhile. Incremental changes (delta sync, LISTEN/NOTIFY) modify the live
+// trie in place, relying on the NodeManager read/write lock, and are
+// serialised with each other by applyMu. All other state is atomic.
 type TrieService struct {
-	nodeManager *trie.NodeManager
+	index            atomic.Pointer[Index]
+	fuzzyMaxDistance atomic.Int32
+	reloading        atomic.Bool
+	// sourceVersion is the version of the source the current trie was built
+	// from, empty when the source is not versioned
+	sourceVersion atomic.Pointer[string]
+	// applyMu serialises incremental changes to the live trie
+	applyMu sync.Mutex
+	// syncDB is the land database of the last delta sync started, read for a
+	// watermark before each reload
+	syncDB atomic.Pointer[sql.DB]
 }
 
 var (
@@ -20,151 +66,324 @@ var (
 // GetTrieService returns singleton instance of TrieService
 func GetTrieService() *TrieService {
 	once.Do(func() {
-		instance = &TrieService{
-			nodeManager: createNodes(),
-		}
+		instance = &TrieService{}
+		instance.fuzzyMaxDistance.Store(DefaultFuzzyMaxDistance)
+		instance.index.Store(NewIndex())
 	})
 	return instance
 }
 
-func createNodes() *trie.NodeManager {
-	nodes := trie.CreateNodes()
-	return &nodes
+// Initialize loads every address from source into a new trie and swaps it in
+func (ts *TrieService) Initialize(ctx context.Context, source database.AddressSource, batchSize int) error {
+	version, err := versionOf(ctx, source)
+	if err != nil {
+		return err
+	}
+	return ts.load(ctx, source, batchSize, version)
 }
 
-func (ts *TrieService) InitializeFromS3(batchSize int) error {
-	// Reset nodes
-	nodes := trie.CreateNodes()
-	ts.nodeManager = &nodes
-
-	// 배치 처리 함수 정의
-	processor := func(addresses []string) error {
-		for i, address := range addresses {
-			// 안전장치: 빈 문자열 체크
-			if len(address) == 0 {
-				log.Printf("ERROR: Empty address found in batch at index %d", i)
-				continue
-			}
+// load builds a trie from source and swaps it in together with its version.
+// An index without any address is never published, so a source that comes
+// back empty leaves the current index serving.
+func (ts *TrieService) load(ctx context.Context, source database.AddressSource, batchSize int, version string) error {
+	// 증분 동기화 중이면 적재 직전의 워터마크를 남겨 교체 후 그 이후 변경만 다시 적용
+	since := ts.syncWatermark(ctx)
 
-			// 디버그: 문제가 될 수 있는 주소 로깅
-			if len(address) < 2 {
-				continue
-			}
-
-			ts.nodeManager.Insert(address)
-		}
-		return nil
+	// 새 트라이에 적재한 뒤 완성되면 교체
+	index, err := Build(ctx, source, batchSize)
+	if err != nil {
+		return err
 	}
-
-	// S3에서 배치로 주소 로드 및 처리
-	if err := database.LoadLandAddressesFromS3Batch(batchSize, processor); err != nil {
-		return fmt.Errorf("failed to load addresses from S3 in batches: %w", err)
+	if index.Jibun.Empty() {
+		return fmt.Errorf("failed to load addresses from %s: %w", source.Name(), ErrEmptyIndex)
 	}
-
-	log.Println("Successfully completed loading all addresses from S3 into trie")
+	index.since = since
 
 	// Trie 상태 출력
-	ts.printTrieStatus()
+	PrintTrieStatus(index)
+
+	ts.index.Store(index)
+	ts.sourceVersion.Store(&version)
 
 	return nil
 }
 
-// InitializeFromDatabase - 기존 DB 방식 (호환성을 위해 유지)
-func (ts *TrieService) InitializeFromDatabase(dbConfig database.Config, batchSize int) error {
-	db, err := database.Connect(dbConfig)
+// Reload rebuilds the trie from source while the current one keeps serving
+// searches. Only one reload runs at a time, and a versioned source that has
+// not changed since the last load is not rebuilt (ErrSourceUnchanged).
+func (ts *TrieService) Reload(ctx context.Context, source database.AddressSource, batchSize int) error {
+	if !ts.reloading.CompareAndSwap(false, true) {
+		return ErrReloadInProgress
+	}
+	defer ts.reloading.Store(false)
+
+	// 소스가 바뀌지 않았으면 재적재 생략
+	version, err := versionOf(ctx, source)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return err
+	}
+	if current := ts.sourceVersion.Load(); version != "" && current != nil && *current == version {
+		log.Printf("%s is unchanged, skipping reload", source.Name())
+		return ErrSourceUnchanged
+	}
+
+	start := time.Now()
+	if err := ts.load(ctx, source, batchSize, version); err != nil {
+		return err
 	}
-	defer db.Close()
 
-	// Reset nodes
-	nodes := trie.CreateNodes()
-	ts.nodeManager = &nodes
+	log.Printf("Trie reloaded from %s in %s", source.Name(), time.Since(start).Round(time.Millisecond))
+	return nil
+}
 
-	// 배치 처리 함수 정의
-	processor := func(addresses []string) error {
-		for _, address := range addresses {
-			ts.nodeManager.Insert(address)
+// StartPeriodicReload reloads the trie from source every interval until stop
+// is called, which also cancels a reload in progress
+func (ts *TrieService) StartPeriodicReload(interval time.Duration, source database.AddressSource, batchSize int) (stop func()) {
+	ticker := time.NewTicker(interval)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := ts.Reload(ctx, source, batchSize); err != nil && !errors.Is(err, ErrSourceUnchanged) {
+					log.Printf("Periodic reload failed: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
 		}
-		return nil
+	}()
+
+	return cancel
+}
+
+// versionOf returns the version of a versioned source, or "" for other sources
+func versionOf(ctx context.Context, source database.AddressSource) (string, error) {
+	versioned, ok := source.(database.VersionedSource)
+	if !ok {
+		return "", nil
 	}
 
-	// 배치로 주소 로드 및 처리
-	if err := database.LoadLandAddressesBatch(db, batchSize, processor); err != nil {
-		return fmt.Errorf("failed to load addresses in batches: %w", err)
+	version, err := versioned.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s for changes: %w", source.Name(), err)
+	}
+	return version, nil
+}
+
+// InitializeFromSnapshot loads a prebuilt trie snapshot instead of rebuilding
+// from raw addresses. Reloads still read the address source passed to Reload,
+// and since a snapshot does not record the version of the source it was built
+// from, the first reload after it always rebuilds.
+func (ts *TrieService) InitializeFromSnapshot(path string) error {
+	start := time.Now()
+
+	index, err := LoadSnapshot(path)
+	if err != nil {
+		return fmt.Errorf("failed to load trie snapshot %s: %w", path, err)
+	}
+	if index.Jibun.Empty() {
+		return fmt.Errorf("failed to load trie snapshot %s: %w", path, ErrEmptyIndex)
 	}
 
-	log.Println("Successfully completed loading all addresses into trie")
+	log.Printf("Successfully loaded trie snapshot %s in %s", path, time.Since(start).Round(time.Millisecond))
 
 	// Trie 상태 출력
-	ts.printTrieStatus()
+	PrintTrieStatus(index)
+
+	ts.index.Store(index)
+	// 스냅샷의 소스 버전은 알 수 없으므로 다음 재적재는 변경 여부를 확인하지 않음
+	ts.sourceVersion.Store(nil)
 
 	return nil
 }
 
-// Search performs search on the trie
-func (ts *TrieService) Search(query string) []string {
-	return ts.nodeManager.Search(query)
+// Suggestion is a single search result with the parcel it refers to. Type is
+// AddressTypeJibun or AddressTypeRoad, and Counterpart is the same parcel's
+// address of the other type, when known.
+type Suggestion struct {
+	Address     string  `json:"address"`
+	Type        string  `json:"type"`
+	Counterpart string  `json:"counterpart,omitempty"`
+	UniqueNo    string  `json:"unique_no,omitempty"`
+	FullCode    string  `json:"full_code,omitempty"`
+	Lat         float64 `json:"lat,omitempty"`
+	Lng         float64 `json:"lng,omitempty"`
+}
+
+// SearchPage is a single page of search results
+type SearchPage struct {
+	Results    []Suggestion
+	NextCursor string
+	// Query is the text that was actually searched
+	Query string
+	// ConvertedFrom holds the original query when it was typed in English
+	// keyboard mode and converted to Hangul, and is empty otherwise
+	ConvertedFrom string
 }
 
-// printTrieStatus prints the current status of the trie
-func (ts *TrieService) printTrieStatus() {
-	log.Println("=== Trie Status ===")
+// Search performs search on the tries of addressType (AddressTypeJibun,
+// AddressTypeRoad or AddressTypeAll; empty means all) and returns the page that
+// starts at cursor. limit is clamped to MaxSearchLimit and defaults to
+// DefaultSearchLimit; the last page within MaxSearchWindow may be shorter and
+// has no next cursor. A cursor is only accepted for the query and address type
+// it was issued for. It holds an offset into the results, so a reload or delta
+// sync between pages can shift them.
+func (ts *TrieService) Search(query string, addressType string, limit int, cursor string) (SearchPage, error) {
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
 
-	// MainNode 상태
-	mainNodeChildrenCount := len(ts.nodeManager.MainNode.Children)
-	log.Printf("MainNode - Children count: %d", mainNodeChildrenCount)
+	key := cursorKey(query, addressType)
+	offset, err := decodeCursor(cursor, key)
+	if err != nil {
+		return SearchPage{}, err
+	}
+	if offset >= MaxSearchWindow {
+		return SearchPage{}, ErrInvalidCursor
+	}
+	// 마지막 페이지는 남은 범위만큼만 반환
+	limit = min(limit, MaxSearchWindow-offset)
 
-	// MainNode의 첫 번째 레벨 자식들 일부 출력
-	if mainNodeChildrenCount > 0 {
-		log.Printf("MainNode - First level children (first 10):")
-		for i, child := range ts.nodeManager.MainNode.Children {
-			if i >= 10 {
-				log.Printf("  ... and %d more children", mainNodeChildrenCount-10)
-				break
+	// 요청 도중 교체되더라도 같은 인덱스로 검색
+	tries, err := ts.index.Load().tries(addressType)
+	if err != nil {
+		return SearchPage{}, err
+	}
+
+	page := SearchPage{Results: make([]Suggestion, 0, limit), Query: query}
+
+	// 다음 페이지 존재 여부 확인을 위해 하나 더 가져오기
+	need := offset + limit + 1
+	results := interleave(tries, func(nodes *trie.NodeManager) []trie.Result {
+		return nodes.Search(query, need)
+	}, need)
+
+	// 한영 전환 없이 입력된 질의는 두벌식 한글로 바꿔 다시 검색하고,
+	// 바꾼 질의로 찾은 결과가 있을 때만 그 질의를 사용
+	if len(results) == 0 {
+		if converted, ok := hangul.FromKeyboard(query); ok {
+			results = interleave(tries, func(nodes *trie.NodeManager) []trie.Result {
+				return nodes.Search(converted, need)
+			}, need)
+			if len(results) > 0 {
+				page.Query = converted
+				page.ConvertedFrom = query
 			}
-			log.Printf("  [%d] '%c' (children: %d, isEnd: %t)", i, child.Value, len(child.Children), child.IsEnd)
 		}
 	}
 
-	// SubNodes 상태
-	subNodesCount := len(ts.nodeManager.SubNodes)
-	log.Printf("SubNodes count: %d", subNodesCount)
+	// 결과가 부족하면 단어 순서와 무관한 검색으로 채우기
+	if len(results) < need {
+		results = appendUnique(results, interleave(tries, func(nodes *trie.NodeManager) []trie.Result {
+			return nodes.SearchTokens(page.Query, need)
+		}, need), need)
+	}
 
-	for i, subNode := range ts.nodeManager.SubNodes {
-		refCount := len(subNode.Ref)
-		log.Printf("SubNode[%d] - References count: %d", i, refCount)
+	// 정확한 검색과 토큰 검색이 모두 실패했을 때만 오타를 허용한 검색
+	if len(results) == 0 {
+		results = ts.appendFuzzy(tries, results, page.Query, need)
+	}
 
-		// 각 SubNode의 참조들 일부 출력
-		if refCount > 0 {
-			log.Printf("  SubNode[%d] references (first 5):", i)
-			for j, ref := range subNode.Ref {
-				if j >= 5 {
-					log.Printf("    ... and %d more references", refCount-5)
-					break
-				}
-				// 참조된 노드의 값과 부모 경로 출력
-				path := ts.getNodePath(ref)
-				log.Printf("    [%d] Node path: '%s' (isEnd: %t)", j, path, ref.IsEnd)
+	if offset >= len(results) {
+		return page, nil
+	}
+
+	end := min(offset+limit, len(results))
+	for _, result := range results[offset:end] {
+		page.Results = append(page.Results, newSuggestion(result))
+	}
+	if len(results) > end && end < MaxSearchWindow {
+		page.NextCursor = encodeCursor(end, key)
+	}
+
+	return page, nil
+}
+
+// SetFuzzyMaxDistance sets the jamo edit distance used by the typo fallback; 0 disables it
+func (ts *TrieService) SetFuzzyMaxDistance(distance int) {
+	ts.fuzzyMaxDistance.Store(int32(distance))
+}
+
+// typedResult is a search result labelled with the type of the trie it came from
+type typedResult struct {
+	trie.Result
+	addressType string
+}
+
+// interleave runs search on every trie and merges the results up to limit,
+// taking one of each type in turn so no type crowds out the others
+func interleave(tries []typedTrie, search func(*trie.NodeManager) []trie.Result, limit int) []typedResult {
+	lists := make([][]trie.Result, len(tries))
+	for i, t := range tries {
+		lists[i] = search(t.nodes)
+	}
+
+	results := make([]typedResult, 0, limit)
+	for i := 0; len(results) < limit; i++ {
+		added := false
+		for j, list := range lists {
+			if i < len(list) && len(results) < limit {
+				results = append(results, typedResult{Result: list[i], addressType: tries[j].addressType})
+				added = true
 			}
 		}
+		if !added {
+			break
+		}
+	}
+	return results
+}
+
+// appendFuzzy fills results up to limit with typo-tolerant matches, closest
+// distance first, skipping addresses that are already present. Search only
+// calls it when exact and token search found nothing.
+func (ts *TrieService) appendFuzzy(tries []typedTrie, results []typedResult, query string, limit int) []typedResult {
+	maxDistance := int(ts.fuzzyMaxDistance.Load())
+	for distance := 1; distance <= maxDistance && len(results) < limit; distance++ {
+		// 이미 찾은 결과를 건너뛸 수 있도록 필요한 만큼 더 가져오기
+		fetch := limit + len(results)
+		results = appendUnique(results, interleave(tries, func(nodes *trie.NodeManager) []trie.Result {
+			return nodes.SearchFuzzy(query, distance, fetch)
+		}, fetch), limit)
 	}
 
-	log.Println("=== End Trie Status ===")
+	return results
 }
 
-// getNodePath returns the path from root to the given node
-func (ts *TrieService) getNodePath(node *trie.FullNode) string {
-	if node == nil {
-		return ""
+// appendUnique appends the extra results not already present until results holds limit
+func appendUnique(results []typedResult, extra []typedResult, limit int) []typedResult {
+	seen := make(map[string]struct{}, len(results))
+	for _, result := range results {
+		seen[result.Address] = struct{}{}
 	}
 
-	path := ""
-	current := node
-	for current != nil && current.Parent != nil {
-		path = string(current.Value) + path
-		current = current.Parent
+	for _, result := range extra {
+		if len(results) >= limit {
+			break
+		}
+		if _, ok := seen[result.Address]; ok {
+			continue
+		}
+		seen[result.Address] = struct{}{}
+		results = append(results, result)
 	}
+	return results
+}
 
-	return path
+func newSuggestion(result typedResult) Suggestion {
+	suggestion := Suggestion{Address: result.Address, Type: result.addressType}
+	if result.Payload != nil {
+		suggestion.Counterpart = result.Payload.Counterpart
+		suggestion.UniqueNo = result.Payload.UniqueNo
+		suggestion.FullCode = result.Payload.FullCode
+		suggestion.Lat = result.Payload.Lat
+		suggestion.Lng = result.Payload.Lng
+	}
+	return suggestion
 }
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin-project/database"
+	"gin-project/trie"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// staticSource is a versioned address source serving fixed addresses
+type staticSource struct {
+	version   string
+	addresses []database.LandAddress
+}
+
+func (source staticSource) Name() string { return "static" }
+
+func (source staticSource) Version(context.Context) (string, error) { return source.version, nil }
+
+func (source staticSource) Load(ctx context.Context, batchSize int, processor func([]database.LandAddress) error) error {
+	for start := 0; start < len(source.addresses); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := processor(source.addresses[start:min(start+batchSize, len(source.addresses))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestReloadRefusesEmptyIndex(t *testing.T) {
+	const address = "서울특별시 강남구 역삼동 737"
+	ts := newTestService(database.LandAddress{Address: address, UniqueNo: "1"})
+
+	if err := ts.Reload(context.Background(), staticSource{version: "v2"}, 10); !errors.Is(err, ErrEmptyIndex) {
+		t.Fatalf("Reload() error = %v, want ErrEmptyIndex", err)
+	}
+	// 빈 소스로는 교체하지 않고 이전 트라이가 계속 검색됨
+	if got, _ := indexed(t, ts, AddressTypeJibun, "1"); got != address {
+		t.Errorf("indexed address after an empty reload = %q, want %q", got, address)
+	}
+}
+
+func TestReloadAfterSnapshot(t *testing.T) {
+	source := staticSource{version: "v1", addresses: []database.LandAddress{
+		{Address: "서울특별시 강남구 역삼동 737", UniqueNo: "1"},
+	}}
+	ts := newTestService()
+	if err := ts.Initialize(context.Background(), source, 10); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if err := ts.Reload(context.Background(), source, 10); !errors.Is(err, ErrSourceUnchanged) {
+		t.Fatalf("Reload() of an unchanged source error = %v, want ErrSourceUnchanged", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "trie.snapshot")
+	if err := ts.index.Load().SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+	if err := ts.InitializeFromSnapshot(path); err != nil {
+		t.Fatalf("InitializeFromSnapshot() error = %v", err)
+	}
+
+	// 스냅샷의 소스 버전은 모르므로 첫 재적재는 다시 만듦
+	if err := ts.Reload(context.Background(), source, 10); err != nil {
+		t.Errorf("first Reload() after a snapshot error = %v, want a rebuild", err)
+	}
+}
+
+// TestConcurrentReload runs reloads and snapshot loads, which swap the index,
+// alongside searches and applied changes; run it with -race. A parcel moved by
+// the changes must be found exactly once in whichever index a search sees.
+func TestConcurrentReload(t *testing.T) {
+	const (
+		movers     = 2
+		searchers  = 2
+		iterations = 100
+		reloads    = 10
+		moved      = "moved"
+	)
+
+	addresses := []database.LandAddress{{Address: "서울특별시 강남구 역삼동 9000", UniqueNo: moved, RoadAddress: "서울특별시 강남구 테헤란로 9000"}}
+	for i := range 200 {
+		addresses = append(addresses, database.LandAddress{
+			Address:     fmt.Sprintf("서울특별시 강남구 역삼동 %d", i),
+			UniqueNo:    fmt.Sprint(i),
+			RoadAddress: fmt.Sprintf("서울특별시 강남구 테헤란로 %d", i),
+		})
+	}
+
+	ts := newTestService()
+	if err := ts.Initialize(context.Background(), staticSource{version: "v0", addresses: addresses}, 50); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "trie.snapshot")
+	if err := ts.index.Load().SaveSnapshot(path); err != nil {
+		t.Fatalf("SaveSnapshot() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, movers+searchers+1)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range reloads {
+			var err error
+			if i%2 == 0 {
+				err = ts.Reload(context.Background(), staticSource{version: fmt.Sprint(i), addresses: addresses}, 50)
+			} else {
+				err = ts.InitializeFromSnapshot(path)
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	for w := range movers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				ts.ApplyChanges([]database.LandChange{{LandAddress: database.LandAddress{
+					UniqueNo:    moved,
+					Address:     fmt.Sprintf("서울특별시 강남구 역삼동 900%d", (w+i)%10),
+					RoadAddress: fmt.Sprintf("서울특별시 강남구 테헤란로 900%d", (w+i)%10),
+				}}})
+			}
+		}()
+	}
+
+	for range searchers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range iterations {
+				for addressType, query := range map[string]string{AddressTypeJibun: "역삼동 900", AddressTypeRoad: "테헤란로 900"} {
+					page, err := ts.Search(query, addressType, MaxSearchLimit, "")
+					if err != nil {
+						errs <- err
+						return
+					}
+					found := 0
+					for _, result := range page.Results {
+						if result.UniqueNo == moved {
+							found++
+						}
+					}
+					if found != 1 {
+						errs <- fmt.Errorf("%s search found the moved parcel %d times: %+v", addressType, found, page.Results)
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	index := ts.index.Load()
+	for _, nodes := range []*trie.NodeManager{index.Jibun, index.Road} {
+		if err := nodes.Validate(); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -1,76 +1,248 @@
 package trie
 
+import (
+	"gin-project/hangul"
+	"slices"
+	"sort"
+)
+
 type FullNode struct {
-	Value    rune
-	Parent   *FullNode
+	Value  rune
+	Parent *FullNode
+	// Children is kept sorted by Value so lookups can use binary search
 	Children []*FullNode
 	IsEnd    bool
+	// id orders the words in the token index postings; it is assigned when
+	// the word is indexed and fits in the padding after IsEnd
+	id uint32
+	// Score ranks the word ending at this node, MaxScore is the highest Score in the subtree
+	Score    float32
+	MaxScore float32
+	// Payload identifies what the word ending at this node refers to
+	Payload *Payload
 }
 
-func (node *FullNode) Insert(word string) {
-	node.insertInternal([]rune(word), 0)
+func (node *FullNode) Insert(word string, score float32, payload *Payload) {
+	node.insertInternal([]rune(word), 0, score, payload)
 }
 
-func (node *FullNode) insertInternal(word []rune, depth int) {
+// insertInternal inserts the rest of word and returns the node it ends at
+func (node *FullNode) insertInternal(word []rune, depth int, score float32, payload *Payload) *FullNode {
+	node.MaxScore = max(node.MaxScore, score)
+
 	if depth == len(word) {
 		node.IsEnd = true
-		return
+		node.Score = score
+		node.Payload = payload
+		return node
 	}
 
 	if node.Children == nil {
 		node.Children = make([]*FullNode, 0)
 	}
 
-	var nextChild *FullNode
+	index := node.childIndex(word[depth])
 
-	for _, child := range node.Children {
-		if word[depth] == child.Value {
-			nextChild = child
-			break
-		}
+	var nextChild *FullNode
+	if index < len(node.Children) && node.Children[index].Value == word[depth] {
+		nextChild = node.Children[index]
 	}
 
 	if nextChild == nil {
 		nextChild = &FullNode{Value: word[depth], Parent: node}
-		node.Children = append(node.Children, nextChild)
+		node.Children = slices.Insert(node.Children, index, nextChild)
 	}
 
-	nextChild.insertInternal(word, depth+1)
+	return nextChild.insertInternal(word, depth+1, score, payload)
+}
+
+// childIndex returns the position of the first child whose value is not less than r
+func (node *FullNode) childIndex(r rune) int {
+	return sort.Search(len(node.Children), func(i int) bool {
+		return node.Children[i].Value >= r
+	})
 }
 
-func (node *FullNode) Search(results *[]string, word string) {
-	runeWord := []rune(word)
-	node.searchInternal(results, runeWord, 0, "")
+// child returns the child holding r, or nil
+func (node *FullNode) child(r rune) *FullNode {
+	index := node.childIndex(r)
+	if index < len(node.Children) && node.Children[index].Value == r {
+		return node.Children[index]
+	}
+	return nil
+}
+
+// candidates returns the children that may match the query rune r: the child
+// holding r itself, or every syllable child starting with r when r is a 초성.
+func (node *FullNode) candidates(r rune) []*FullNode {
+	first, last, ok := hangul.ChoseongRange(r)
+	if !ok {
+		index := node.childIndex(r)
+		if index < len(node.Children) && node.Children[index].Value == r {
+			return node.Children[index : index+1]
+		}
+		return nil
+	}
+
+	// 초성 자체와 그 초성으로 시작하는 음절을 모두 포함
+	from := node.childIndex(r)
+	to := from
+	for to < len(node.Children) && node.Children[to].Value == r {
+		to++
+	}
+	if to > from {
+		return append(slices.Clip(node.Children[from:to]), node.childrenBetween(first, last)...)
+	}
+	return node.childrenBetween(first, last)
 }
 
-func (node *FullNode) searchInternal(results *[]string, word []rune, depth int, result string) {
+// childrenBetween returns the children whose values lie in [first, last]
+func (node *FullNode) childrenBetween(first, last rune) []*FullNode {
+	from := node.childIndex(first)
+	to := node.childIndex(last + 1)
+	return node.Children[from:to]
+}
+
+// Search appends up to limit words starting with the given prefix to results,
+// highest score first.
+func (node *FullNode) Search(results *[]Result, word string, limit int) {
+	matches := make([]match, 0)
+	node.searchInternal(&matches, []rune(word), 0, "")
+	*results = append(*results, rankMatches(limit-len(*results), matches)...)
+}
+
+func (node *FullNode) searchInternal(matches *[]match, word []rune, depth int, result string) {
 	if depth != 0 {
 		result += string(node.Value)
 	}
 
-	if depth <= len(word)-1 {
+	if depth > len(word)-1 {
+		*matches = append(*matches, match{node: node, word: result})
+		return
+	}
+
+	// 초성이 섞인 질의는 여러 자식과 일치할 수 있으므로 모두 탐색
+	for _, child := range node.candidates(word[depth]) {
+		child.searchInternal(matches, word, depth+1, result)
+	}
+
+	// 초성이 섞인 단어는 주소 단어의 앞부분으로 보고 나머지를 건너뜀 (서ㅇ 강남 -> 서울특별시 강남구)
+	if word[depth] == ' ' && depth > 0 && node.Value != ' ' && hasChoseong(word[wordStart(word, depth):depth]) {
 		for _, child := range node.Children {
-			if child.Value == word[depth] {
-				child.searchInternal(results, word, depth+1, result)
-				break
+			if child.Value != ' ' {
+				child.skipWord(matches, word, depth, result)
 			}
 		}
-	} else {
-		if node.IsEnd {
-			*results = append(*results, result)
+	}
+
+	if depth != len(word)-1 {
+		return
+	}
+
+	// 마지막 글자는 조합 중일 수 있으므로 자모 단위로 비교
+	keys := hangul.Decompose(word[depth])
+	first, last, ok := hangul.ChoseongRange(keys[0])
+	if !ok {
+		return
+	}
+	for _, child := range node.childrenBetween(first, last) {
+		if !matchRune(word[depth], child.Value) {
+			child.searchPartial(matches, keys, result)
+		}
+	}
+}
+
+// skipWord follows the rest of an address word and resumes matching word at
+// depth, a space, once the address word ends.
+func (node *FullNode) skipWord(matches *[]match, word []rune, depth int, result string) {
+	result += string(node.Value)
+	for _, child := range node.Children {
+		if child.Value == ' ' {
+			child.searchInternal(matches, word, depth+1, result)
+		} else {
+			child.skipWord(matches, word, depth, result)
 		}
+	}
+}
 
-		if node.Children == nil {
-			return
+// wordStart returns the index where the query word ending before depth begins
+func wordStart(word []rune, depth int) int {
+	for i := depth - 1; i >= 0; i-- {
+		if word[i] == ' ' {
+			return i + 1
 		}
+	}
+	return 0
+}
 
-		for _, child := range node.Children {
-			if len(*results) >= 5 {
-				return
-			}
-			child.searchInternal(results, word, depth+1, result)
+// hasChoseong reports whether runes contains a 초성 typed on its own
+func hasChoseong(runes []rune) bool {
+	for _, r := range runes {
+		if _, _, ok := hangul.ChoseongRange(r); ok {
+			return true
 		}
 	}
+	return false
+}
+
+// searchPartial matches the jamo keys of an unfinished syllable against this
+// node, continuing into the children when the keys spill over into the next
+// syllable (e.g. 간 while typing 가나).
+func (node *FullNode) searchPartial(matches *[]match, keys []rune, result string) {
+	value := hangul.Decompose(node.Value)
+
+	if hasRunePrefix(value, keys) {
+		*matches = append(*matches, match{node: node, word: result + string(node.Value)})
+		return
+	}
+
+	if len(keys) <= len(value) || !hasRunePrefix(keys, value) {
+		return
+	}
+
+	result += string(node.Value)
+	rest := keys[len(value):]
+	first, last, ok := hangul.ChoseongRange(rest[0])
+	if !ok {
+		return
+	}
+	for _, child := range node.childrenBetween(first, last) {
+		child.searchPartial(matches, rest, result)
+	}
+}
+
+// prune unlinks the node and every ancestor left without a word or children,
+// recomputes MaxScore up to the root and returns the unlinked nodes.
+func (node *FullNode) prune() []*FullNode {
+	removed := make([]*FullNode, 0)
+
+	current := node
+	for current.Parent != nil && !current.IsEnd && len(current.Children) == 0 {
+		parent := current.Parent
+		index := parent.childIndex(current.Value)
+		parent.Children = slices.Delete(parent.Children, index, index+1)
+		removed = append(removed, current)
+		current = parent
+	}
+
+	// 삭제된 단어의 점수가 더 이상 상한에 남지 않도록 갱신
+	for ; current != nil; current = current.Parent {
+		current.refreshMaxScore()
+	}
+
+	return removed
+}
+
+// refreshMaxScore recomputes MaxScore from the node and its children
+func (node *FullNode) refreshMaxScore() {
+	best := float32(0)
+	if node.IsEnd {
+		best = node.Score
+	}
+	for _, child := range node.Children {
+		best = max(best, child.MaxScore)
+	}
+	node.MaxScore = best
 }
 
 func (node *FullNode) searchNode(word string) *FullNode {
@@ -83,22 +255,25 @@ func (node *FullNode) searchNodeInternal(word []rune, depth int) *FullNode {
 		return node
 	}
 
-	for _, child := range node.Children {
-		if child.Value == word[depth] {
-			return child.searchNodeInternal(word, depth+1)
-		}
+	if child := node.child(word[depth]); child != nil {
+		return child.searchNodeInternal(word, depth+1)
 	}
 
 	return nil
 }
 
-func (node *FullNode) searchInMiddle(results *[]string, word []rune) {
-	if node.Value != word[0] {
+func (node *FullNode) searchInMiddle(matches *[]match, word []rune) {
+	if matchRune(word[0], node.Value) {
+		node.searchInternal(matches, word, 1, node.combineParentValues())
 		return
 	}
-	result := node.combineParentValues()
 
-	node.searchInternal(results, word, 1, result)
+	if len(word) == 1 {
+		keys := hangul.Decompose(word[0])
+		if hangul.Choseong(node.Value) == keys[0] {
+			node.searchPartial(matches, keys, node.combineParentValues())
+		}
+	}
 }
 
 func (node *FullNode) combineParentValues() string {
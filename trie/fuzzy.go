@@ -0,0 +1,126 @@
+package trie
+
+import "gin-project/hangul"
+
+// fuzzyMatcher walks the trie with a Damerau-Levenshtein (optimal string
+// alignment) automaton over jamo, so 강남규 is one edit away from 강남구.
+type fuzzyMatcher struct {
+	keys        []rune
+	maxDistance int
+	// rows holds the automaton row for each number of jamo consumed; a depth
+	// first walk only needs the rows along the current path
+	rows [][]int
+}
+
+// fuzzyState is the automaton state after consuming the path up to a node.
+// prev and last are kept to detect transpositions of adjacent jamo.
+type fuzzyState struct {
+	prev  []int
+	row   []int
+	last  rune
+	depth int
+}
+
+func newFuzzyMatcher(query string, maxDistance int) *fuzzyMatcher {
+	return &fuzzyMatcher{keys: hangul.DecomposeString(query), maxDistance: maxDistance}
+}
+
+// row returns the buffer for the row after depth jamo
+func (m *fuzzyMatcher) row(depth int) []int {
+	for len(m.rows) <= depth {
+		m.rows = append(m.rows, make([]int, len(m.keys)+1))
+	}
+	return m.rows[depth]
+}
+
+func (m *fuzzyMatcher) start() fuzzyState {
+	row := m.row(0)
+	for i := range row {
+		row[i] = i
+	}
+	return fuzzyState{row: row}
+}
+
+func (m *fuzzyMatcher) step(state fuzzyState, c rune) fuzzyState {
+	row := m.row(state.depth + 1)
+	row[0] = state.row[0] + 1
+
+	for i := 1; i <= len(m.keys); i++ {
+		cost := 1
+		if m.keys[i-1] == c {
+			cost = 0
+		}
+		row[i] = min(state.row[i]+1, row[i-1]+1, state.row[i-1]+cost)
+
+		if i > 1 && state.prev != nil && m.keys[i-1] == state.last && m.keys[i-2] == c {
+			row[i] = min(row[i], state.prev[i-2]+1)
+		}
+	}
+
+	return fuzzyState{prev: state.row, row: row, last: c, depth: state.depth + 1}
+}
+
+// canStart reports whether a word starting with r can match: its first jamo
+// must be one of the first maxDistance+1 jamo of the query, so a typo in the
+// first letter of a word is not corrected
+func (m *fuzzyMatcher) canStart(r rune) bool {
+	first := hangul.Choseong(r)
+	for _, key := range m.keys[:min(len(m.keys), m.maxDistance+1)] {
+		if key == first {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the path consumed so far is within distance of the whole query
+func (m *fuzzyMatcher) matches(state fuzzyState) bool {
+	return state.row[len(m.keys)] <= m.maxDistance
+}
+
+// viable reports whether any continuation of the path can still match
+func (m *fuzzyMatcher) viable(state fuzzyState) bool {
+	for _, distance := range state.row {
+		if distance <= m.maxDistance {
+			return true
+		}
+	}
+	return false
+}
+
+// searchFuzzy feeds this node's jamo to the automaton and records the node as
+// a match as soon as the path is close enough to the query.
+func (node *FullNode) searchFuzzy(matches *[]match, matcher *fuzzyMatcher, state fuzzyState) {
+	for _, key := range hangul.Decompose(node.Value) {
+		state = matcher.step(state, key)
+		if matcher.matches(state) {
+			// 경로 문자열은 일치한 노드에서만 만듦
+			*matches = append(*matches, match{node: node, word: node.combineParentsInternal("")})
+			return
+		}
+		if !matcher.viable(state) {
+			return
+		}
+	}
+
+	for _, child := range node.Children {
+		child.searchFuzzy(matches, matcher, state)
+	}
+}
+
+// SearchFuzzy appends up to limit words starting with a prefix that is within
+// maxDistance jamo edits of the given word, highest score first.
+func (node *FullNode) SearchFuzzy(results *[]Result, word string, maxDistance int, limit int) {
+	matches := make([]match, 0)
+	node.matchFuzzy(&matches, newFuzzyMatcher(word, maxDistance))
+	*results = append(*results, rankMatches(limit-len(*results), matches)...)
+}
+
+func (node *FullNode) matchFuzzy(matches *[]match, matcher *fuzzyMatcher) {
+	state := matcher.start()
+	for _, child := range node.Children {
+		if matcher.canStart(child.Value) {
+			child.searchFuzzy(matches, matcher, state)
+		}
+	}
+}
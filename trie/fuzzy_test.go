@@ -0,0 +1,50 @@
+package trie
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchFuzzy(t *testing.T) {
+	nodes := CreateNodes()
+	for _, address := range []string{
+		"서울특별시 강남구 역삼동 737",
+		"서울특별시 강남구 삼성동 12",
+		"서울특별시 서초구 서초동 12",
+		"부산광역시 강서구 대저동 1",
+	} {
+		nodes.Insert(address, 0, nil)
+	}
+
+	tests := []struct {
+		query       string
+		maxDistance int
+		limit       int
+		want        []string
+	}{
+		// 자모 하나 치환
+		{"강남규", 1, 10, []string{"서울특별시 강남구 삼성동 12", "서울특별시 강남구 역삼동 737"}},
+		// 이웃한 자모의 자리 바꿈 (ㅇㄴ → ㄴㅇ)
+		{"간암구", 1, 10, []string{"서울특별시 강남구 삼성동 12", "서울특별시 강남구 역삼동 737"}},
+		// 주소의 첫 단어
+		{"서울특별사", 1, 10, []string{
+			"서울특별시 강남구 삼성동 12", "서울특별시 강남구 역삼동 737", "서울특별시 서초구 서초동 12",
+		}},
+		{"부산광역시 강서구 대저둥", 1, 10, []string{"부산광역시 강서구 대저동 1"}},
+		{"서울특별사", 1, 1, []string{"서울특별시 강남구 삼성동 12"}},
+		// 단어의 첫 자모가 틀리면 찾지 않음
+		{"항남구", 1, 10, []string{}},
+		// 거리를 넘는 오타
+		{"강놈규", 1, 10, []string{}},
+		// 짧은 검색어는 허용 거리가 줄어듦
+		{"가", 1, 10, []string{}},
+		{"강남규", 0, 10, []string{}},
+	}
+
+	for _, tt := range tests {
+		got := addresses(nodes.SearchFuzzy(tt.query, tt.maxDistance, tt.limit))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SearchFuzzy(%q, %d, %d) = %q, want %q", tt.query, tt.maxDistance, tt.limit, got, tt.want)
+		}
+	}
+}
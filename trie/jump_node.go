@@ -1,5 +1,7 @@
 package trie
 
+import "slices"
+
 type JumpNode struct {
 	Ref []*FullNode
 }
@@ -49,13 +51,41 @@ func (node *JumpNode) containsRef(target *FullNode) bool {
 	return false
 }
 
-func (node *JumpNode) Search(results *[]string, word string) {
+// removeRefs drops the references to the given nodes
+func (node *JumpNode) removeRefs(targets map[*FullNode]struct{}) {
+	node.Ref = slices.DeleteFunc(node.Ref, func(ref *FullNode) bool {
+		_, ok := targets[ref]
+		return ok
+	})
+}
+
+// Search appends up to limit words that contain the given word right after a space.
+func (node *JumpNode) Search(results *[]Result, word string, limit int) {
+	matches := make([]match, 0)
+	node.match(&matches, []rune(word))
+	*results = append(*results, rankMatches(limit-len(*results), matches)...)
+}
+
+func (node *JumpNode) match(matches *[]match, word []rune) {
 	for _, refNode := range node.Ref {
-		runeWord := []rune(word)
+		refNode.searchInMiddle(matches, word)
+	}
+}
 
-		refNode.searchInMiddle(results, runeWord)
-		if len(*results) >= 5 {
-			break
+// SearchFuzzy appends up to limit words containing, right after a space, a
+// prefix within maxDistance jamo edits of the given word.
+func (node *JumpNode) SearchFuzzy(results *[]Result, word string, maxDistance int, limit int) {
+	matches := make([]match, 0)
+	node.matchFuzzy(&matches, newFuzzyMatcher(word, maxDistance))
+	*results = append(*results, rankMatches(limit-len(*results), matches)...)
+}
+
+func (node *JumpNode) matchFuzzy(matches *[]match, matcher *fuzzyMatcher) {
+	state := matcher.start()
+	for _, refNode := range node.Ref {
+		// 첫 자모가 맞지 않는 단어는 오토마톤을 돌리지 않고 건너뜀
+		if matcher.canStart(refNode.Value) {
+			refNode.searchFuzzy(matches, matcher, state)
 		}
 	}
 }
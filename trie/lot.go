@@ -0,0 +1,173 @@
+package trie
+
+import (
+	"strings"
+	"unicode"
+)
+
+// mountainPrefix marks a lot number on a mountain parcel (산 12-3)
+const mountainPrefix = '산'
+
+// lotQuery is a query that ends with a lot number (지번) such as "12",
+// "12-3", "산12" or "산 12-3"
+type lotQuery struct {
+	// base is the text before the lot number, including the trailing space
+	base     string
+	mountain bool
+	// number is the lot number as typed, e.g. "12" or "12-3"
+	number string
+}
+
+// parseLotQuery splits the trailing lot number off a query
+func parseLotQuery(query string) (lotQuery, bool) {
+	query = strings.TrimRight(query, " ")
+	cut := strings.LastIndexByte(query, ' ')
+	base, last := query[:cut+1], query[cut+1:]
+
+	lot := lotQuery{base: base}
+	if rest, ok := strings.CutPrefix(last, string(mountainPrefix)); ok {
+		lot.mountain = true
+		last = rest
+		// "산 12"처럼 띄어 쓴 경우
+		if last == "" {
+			return lotQuery{}, false
+		}
+	} else if before, ok := strings.CutSuffix(strings.TrimRight(base, " "), string(mountainPrefix)); ok && (before == "" || strings.HasSuffix(before, " ")) {
+		lot.mountain = true
+		lot.base = before
+	}
+
+	if !isLotNumber(last) {
+		return lotQuery{}, false
+	}
+	lot.number = last
+	return lot, true
+}
+
+// isLotNumber reports whether s is a main number optionally followed by a
+// dash and a sub number, e.g. "12", "12-" or "12-3"
+func isLotNumber(s string) bool {
+	main, sub, _ := strings.Cut(s, "-")
+	return main != "" && isDigits(main) && isDigits(sub)
+}
+
+// isLotToken reports whether an address word is (part of) a lot number
+func isLotToken(word string) bool {
+	if word == string(mountainPrefix) {
+		return true
+	}
+	word = strings.TrimPrefix(word, string(mountainPrefix))
+	return word != "" && unicode.IsDigit([]rune(word)[0])
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// forms returns the spellings to search for: mountain lots are stored either
+// as "산 12" or "산12"
+func (lot lotQuery) forms() []string {
+	if !lot.mountain {
+		return []string{lot.base + lot.number}
+	}
+	return []string{
+		lot.base + string(mountainPrefix) + " " + lot.number,
+		lot.base + string(mountainPrefix) + lot.number,
+	}
+}
+
+// mountainForms returns the mountain spellings of a plain lot query
+func (lot lotQuery) mountainForms() []string {
+	mountain := lot
+	mountain.mountain = true
+	return mountain.forms()
+}
+
+// restrict narrows matches for a lot number without a sub number to that exact
+// main number: 12 keeps 12 and 12-1..12-99 but drops 120 and 123-4.
+func (lot lotQuery) restrict(matches []match) []match {
+	if strings.Contains(lot.number, "-") {
+		return matches
+	}
+
+	restricted := make([]match, 0, len(matches))
+	for _, m := range matches {
+		node, ok := m.node.(*FullNode)
+		if !ok {
+			restricted = append(restricted, m)
+			continue
+		}
+		if node.IsEnd {
+			restricted = append(restricted, match{node: wordNode{node}, word: m.word})
+		}
+		if dash := node.child('-'); dash != nil {
+			restricted = append(restricted, match{node: dash, word: m.word + "-"})
+		}
+	}
+	return restricted
+}
+
+// wordNode ranks only the word ending at a node, not its subtree
+type wordNode struct {
+	*FullNode
+}
+
+func (node wordNode) bestScore() float32 {
+	return node.Score
+}
+
+func (node wordNode) expand(push func(child rankNode, label string)) {}
+
+// compareNatural orders strings rune by rune but compares runs of digits by
+// their numeric value, so "역삼동 12" < "역삼동 12-2" < "역삼동 12-10" < "역삼동 100".
+// A string sorts before every string it is a prefix of.
+func compareNatural(a, b string) int {
+	// UTF-8은 바이트 순서가 글자 순서와 같고 숫자 바이트는 다른 글자 안에 나오지 않음
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(rune(a[i])) && isDigit(rune(b[j])) {
+			startA, startB := i, j
+			for i < len(a) && isDigit(rune(a[i])) {
+				i++
+			}
+			for j < len(b) && isDigit(rune(b[j])) {
+				j++
+			}
+			if c := compareNumbers(a[startA:i], b[startB:j]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	return (len(a) - i) - (len(b) - j)
+}
+
+// compareNumbers compares two runs of digits by value, the shorter run first
+// when equal (0 before 00)
+func compareNumbers(a, b string) int {
+	trimmedA, trimmedB := strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(trimmedA) != len(trimmedB) {
+		return len(trimmedA) - len(trimmedB)
+	}
+	if c := strings.Compare(trimmedA, trimmedB); c != 0 {
+		return c
+	}
+	return len(a) - len(b)
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
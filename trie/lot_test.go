@@ -0,0 +1,126 @@
+package trie
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseLotQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  lotQuery
+		ok    bool
+	}{
+		{"역삼동 12", lotQuery{base: "역삼동 ", number: "12"}, true},
+		{"역삼동 12-3", lotQuery{base: "역삼동 ", number: "12-3"}, true},
+		// 부번을 입력하는 중
+		{"역삼동 12-", lotQuery{base: "역삼동 ", number: "12-"}, true},
+		{"역삼동 012", lotQuery{base: "역삼동 ", number: "012"}, true},
+		{"역삼동 12  ", lotQuery{base: "역삼동 ", number: "12"}, true},
+		{"12", lotQuery{number: "12"}, true},
+		{"역삼동 산12", lotQuery{base: "역삼동 ", mountain: true, number: "12"}, true},
+		{"역삼동 산 12-3", lotQuery{base: "역삼동 ", mountain: true, number: "12-3"}, true},
+		{"산 12", lotQuery{mountain: true, number: "12"}, true},
+		// 산으로 끝나는 단어는 산 지번이 아님
+		{"부산 12", lotQuery{base: "부산 ", number: "12"}, true},
+		{"테헤란로 12", lotQuery{base: "테헤란로 ", number: "12"}, true},
+		{"역삼동 산", lotQuery{}, false},
+		{"역삼동", lotQuery{}, false},
+		{"역삼동 12a", lotQuery{}, false},
+		{"역삼동 -3", lotQuery{}, false},
+		{"역삼동 12-3-4", lotQuery{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseLotQuery(tt.query)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseLotQuery(%q) = %+v, %t, want %+v, %t", tt.query, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSearchLot(t *testing.T) {
+	nodes := CreateNodes()
+	for _, address := range []string{
+		"서울특별시 강남구 역삼동 12",
+		"서울특별시 강남구 역삼동 12-3",
+		"서울특별시 강남구 역삼동 12-10",
+		"서울특별시 강남구 역삼동 120",
+		"서울특별시 강남구 역삼동 123-4",
+		"서울특별시 강남구 역삼동 산 12",
+		"서울특별시 강남구 역삼동 산12-1",
+		"서울특별시 강남구 역삼동 산 120",
+		"서울특별시 강남구 테헤란로 12",
+		"서울특별시 강남구 테헤란로 12-3",
+		"서울특별시 강남구 테헤란로 120",
+	} {
+		nodes.Insert(address, 0, nil)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// 같은 본번, 같은 본번의 산 지번, 더 긴 본번 순서
+		{"역삼동 12", []string{
+			"서울특별시 강남구 역삼동 12", "서울특별시 강남구 역삼동 12-3", "서울특별시 강남구 역삼동 12-10",
+			"서울특별시 강남구 역삼동 산 12", "서울특별시 강남구 역삼동 산12-1",
+			"서울특별시 강남구 역삼동 120", "서울특별시 강남구 역삼동 123-4",
+		}},
+		{"역삼동 12-", []string{
+			"서울특별시 강남구 역삼동 12-3", "서울특별시 강남구 역삼동 12-10", "서울특별시 강남구 역삼동 산12-1",
+		}},
+		{"역삼동 12-3", []string{"서울특별시 강남구 역삼동 12-3"}},
+		{"역삼동 산12", []string{"서울특별시 강남구 역삼동 산 12", "서울특별시 강남구 역삼동 산12-1"}},
+		{"역삼동 산 12", []string{"서울특별시 강남구 역삼동 산 12", "서울특별시 강남구 역삼동 산12-1"}},
+		{"역삼동 012", []string{}},
+		// 도로명주소의 건물번호도 같은 순서
+		{"테헤란로 12", []string{
+			"서울특별시 강남구 테헤란로 12", "서울특별시 강남구 테헤란로 12-3", "서울특별시 강남구 테헤란로 120",
+		}},
+	}
+
+	for _, tt := range tests {
+		if got := addresses(nodes.Search(tt.query, 10)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"역삼동 12", "역삼동 12", 0},
+		{"역삼동 12", "역삼동 120", -1},
+		{"역삼동 12", "역삼동 100", -1},
+		{"역삼동 12", "역삼동 12-2", -1},
+		{"역삼동 12-2", "역삼동 12-10", -1},
+		{"역삼동 9", "역삼동 012", -1},
+		// 값이 같으면 짧은 쪽이 먼저
+		{"역삼동 12", "역삼동 012", -1},
+		{"역삼동 0", "역삼동 00", -1},
+		{"역삼동 12", "역삼동 산 12", -1},
+		{"개포동 900", "역삼동 1", -1},
+		{"테헤란로 12-3", "테헤란로 120", -1},
+	}
+
+	sign := func(c int) int {
+		switch {
+		case c < 0:
+			return -1
+		case c > 0:
+			return 1
+		}
+		return 0
+	}
+	for _, tt := range tests {
+		if got := sign(compareNatural(tt.a, tt.b)); got != tt.want {
+			t.Errorf("compareNatural(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := sign(compareNatural(tt.b, tt.a)); got != -tt.want {
+			t.Errorf("compareNatural(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
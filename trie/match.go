@@ -0,0 +1,25 @@
+package trie
+
+import "gin-project/hangul"
+
+// matchRune reports whether a query rune matches a rune stored in the trie.
+// A bare initial consonant (초성) in the query matches any syllable that starts with it.
+func matchRune(query, value rune) bool {
+	if query == value {
+		return true
+	}
+	return hangul.IsChoseong(query) && hangul.Choseong(value) == query
+}
+
+// hasRunePrefix reports whether s begins with prefix
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) > len(s) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
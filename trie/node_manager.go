@@ -1,25 +1,229 @@
 package trie
 
 import (
+	"gin-project/hangul"
 	"strings"
-	"unicode"
+	"sync"
 )
 
+// NodeManager is the address index: MainNode holds every address and
+// SubNodes[i] points at the nodes where word i+1 of an address begins.
+//
+// Its methods are safe for concurrent use. Search, SearchFuzzy, SearchTokens,
+// Parcel, Stats, Validate and WriteSnapshot hold a read lock for the whole
+// traversal, while Insert, Update, Upsert, Delete and DeleteParcel hold the
+// write lock, so a search sees each change either completely or not at all.
+// Payloads are never modified once inserted, so results may keep referring to
+// them after the lock is released. MainNode and SubNodes are exported for
+// inspection and must not be accessed directly while another goroutine may
+// modify the trie.
 type NodeManager struct {
 	MainNode FullNode
 	SubNodes []JumpNode
+
+	// mu guards MainNode, SubNodes, parcels and the token index
+	mu sync.RWMutex
+	// parcels maps the unique_no of each payload to its address
+	parcels map[string]string
+	// tokens is the root of the token index used by SearchTokens, and words
+	// maps the ids in its postings to the words, nil once deleted
+	tokens tokenNode
+	words  []*FullNode
 }
 
-func CreateNodes() NodeManager {
-	return NodeManager{FullNode{}, make([]JumpNode, 0)}
+func CreateNodes() *NodeManager {
+	return &NodeManager{
+		MainNode: FullNode{},
+		SubNodes: make([]JumpNode, 0),
+		parcels:  make(map[string]string),
+		// id 0은 쓰지 않음
+		words: []*FullNode{nil},
+	}
 }
 
-func (nodes *NodeManager) Insert(address string) {
+// Insert adds an address with the score used to rank it among other suggestions
+// and an optional payload returned alongside it.
+func (nodes *NodeManager) Insert(address string, score float32, payload *Payload) {
 	// 안전장치: 빈 문자열 체크
 	if len(address) == 0 {
 		return
 	}
-	
+
+	nodes.mu.Lock()
+	defer nodes.mu.Unlock()
+
+	nodes.insert(address, score, payload)
+}
+
+func (nodes *NodeManager) insert(address string, score float32, payload *Payload) {
+	maxDepth := jumpDepth(address)
+
+	// 같은 주소를 다시 넣으면 이전 필지 정보는 덮어씀
+	existing := nodes.MainNode.searchNode(address)
+	exists := existing != nil && existing.IsEnd
+	if exists {
+		nodes.unindex(existing.Payload, address)
+	}
+	if payload != nil && payload.UniqueNo != "" {
+		nodes.parcels[payload.UniqueNo] = address
+	}
+
+	word := nodes.MainNode.insertInternal([]rune(address), 0, score, payload)
+	if !exists {
+		nodes.addTokens(address, word)
+	}
+
+	for i := 0; i < maxDepth; i++ {
+		if len(nodes.SubNodes) < i+1 {
+			nodes.SubNodes = append(nodes.SubNodes, CreateJumpNode())
+		}
+		nodes.SubNodes[i].Insert(&nodes.MainNode, address, i+1)
+	}
+}
+
+// Delete removes an address so it is no longer suggested and reports whether
+// it was present. Nodes left without words are pruned together with the jump
+// references pointing at them.
+func (nodes *NodeManager) Delete(address string) bool {
+	nodes.mu.Lock()
+	defer nodes.mu.Unlock()
+
+	_, _, ok := nodes.delete(address)
+	return ok
+}
+
+// Update renames the address old to new, keeping its score and payload, and
+// reports whether old was present. Searches never see the address missing.
+func (nodes *NodeManager) Update(old, new string) bool {
+	if len(new) == 0 {
+		return false
+	}
+
+	nodes.mu.Lock()
+	defer nodes.mu.Unlock()
+
+	score, payload, ok := nodes.delete(old)
+	if ok {
+		nodes.insert(new, score, payload)
+	}
+	return ok
+}
+
+// Upsert indexes the parcel of payload at address, moving it away from the
+// address it was indexed at before. Searches never see the parcel missing or
+// at both addresses.
+func (nodes *NodeManager) Upsert(address string, score float32, payload *Payload) {
+	if len(address) == 0 {
+		return
+	}
+
+	nodes.mu.Lock()
+	defer nodes.mu.Unlock()
+
+	if payload != nil && payload.UniqueNo != "" {
+		if previous, ok := nodes.parcels[payload.UniqueNo]; ok && previous != address {
+			nodes.delete(previous)
+		}
+	}
+	nodes.insert(address, score, payload)
+}
+
+// DeleteParcel removes the address indexed for a parcel unique_no and reports
+// whether the parcel was indexed
+func (nodes *NodeManager) DeleteParcel(uniqueNo string) bool {
+	nodes.mu.Lock()
+	defer nodes.mu.Unlock()
+
+	address, ok := nodes.parcels[uniqueNo]
+	if !ok {
+		return false
+	}
+	_, _, ok = nodes.delete(address)
+	return ok
+}
+
+// delete removes the address and returns the score and payload it had
+func (nodes *NodeManager) delete(address string) (float32, *Payload, bool) {
+	node := nodes.MainNode.searchNode(address)
+	if node == nil || !node.IsEnd {
+		return 0, nil, false
+	}
+
+	score, payload := node.Score, node.Payload
+	nodes.unindex(payload, address)
+	nodes.removeTokens(address, node)
+	node.IsEnd = false
+	node.Score = 0
+	node.Payload = nil
+
+	// 빈 가지를 잘라내고 그 노드를 가리키던 점프 참조 제거
+	// 점프 참조는 공백 바로 뒤의 노드만 가리키므로 그런 노드가 잘렸을 때만 찾아봄
+	targets := make(map[*FullNode]struct{})
+	for _, pruned := range node.prune() {
+		if pruned.Parent.Value == ' ' {
+			targets[pruned] = struct{}{}
+		}
+	}
+	if len(targets) > 0 {
+		for i := range nodes.SubNodes {
+			nodes.SubNodes[i].removeRefs(targets)
+		}
+	}
+
+	return score, payload, true
+}
+
+// Empty reports whether the trie holds no address
+func (nodes *NodeManager) Empty() bool {
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	// 지운 주소의 가지는 잘라내므로 루트에 자식이 없으면 주소도 없음
+	return len(nodes.MainNode.Children) == 0
+}
+
+// Parcel returns the address currently indexed for a parcel unique_no
+func (nodes *NodeManager) Parcel(uniqueNo string) (string, bool) {
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	address, ok := nodes.parcels[uniqueNo]
+	return address, ok
+}
+
+// ParcelCount returns the number of parcels indexed by unique_no
+func (nodes *NodeManager) ParcelCount() int {
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	return len(nodes.parcels)
+}
+
+// unindex drops the parcel index entry of payload if it still points at address
+func (nodes *NodeManager) unindex(payload *Payload, address string) {
+	if payload == nil || payload.UniqueNo == "" {
+		return
+	}
+	if nodes.parcels[payload.UniqueNo] == address {
+		delete(nodes.parcels, payload.UniqueNo)
+	}
+}
+
+// reindex rebuilds the parcel and token indexes from the words in the trie
+func (nodes *NodeManager) reindex() {
+	nodes.reindexTokens()
+
+	nodes.parcels = make(map[string]string)
+	nodes.MainNode.walk(func(node *FullNode) {
+		if node.IsEnd && node.Payload != nil && node.Payload.UniqueNo != "" {
+			nodes.parcels[node.Payload.UniqueNo] = node.combineParentsInternal("")
+		}
+	})
+}
+
+// jumpDepth returns the index of the last word of the address that is not part
+// of the lot number (digits or 산); words before it get a jump reference.
+func jumpDepth(address string) int {
 	split := strings.Split(address, " ")
 
 	maxDepth := -1
@@ -28,33 +232,91 @@ func (nodes *NodeManager) Insert(address string) {
 		if len(splitAddress) == 0 {
 			continue
 		}
-		
-		runes := []rune(splitAddress)
-		if len(runes) > 0 && !unicode.IsDigit(runes[0]) {
+
+		if !isLotToken(splitAddress) {
 			maxDepth = i
 		}
 	}
 
-	nodes.MainNode.Insert(address)
+	return maxDepth
+}
 
-	for i := 0; i < maxDepth; i++ {
-		if len(nodes.SubNodes) < i+1 {
-			nodes.SubNodes = append(nodes.SubNodes, CreateJumpNode())
-		}
-		nodes.SubNodes[i].Insert(&nodes.MainNode, address, i+1)
+// Search returns up to limit addresses matching the query, prefix matches first
+// and then matches that start after a space, each ranked by score.
+//
+// A query ending in a lot number ranks that main number first: "역삼동 12"
+// finds 12 and 12-1..12-99, then the mountain lots 산 12, and only then lots
+// that merely start with the digits such as 120. "역삼동 산12" finds mountain
+// lots only.
+func (nodes *NodeManager) Search(query string, limit int) []Result {
+	if limit <= 0 || query == "" {
+		return make([]Result, 0)
+	}
+
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	lot, ok := parseLotQuery(query)
+	if !ok {
+		prefix, middle := nodes.match([]rune(query))
+		return rankMatches(limit, prefix, middle)
+	}
+
+	prefix, middle := nodes.matchLot(lot, lot.forms())
+	if lot.mountain {
+		return rankMatches(limit, prefix, middle)
 	}
+
+	// 일반 지번 뒤에 같은 본번의 산 지번, 마지막으로 입력 중인 더 긴 본번
+	sanPrefix, sanMiddle := nodes.matchLot(lot, lot.mountainForms())
+	longerPrefix, longerMiddle := nodes.match([]rune(query))
+	return rankMatches(limit, prefix, middle, sanPrefix, sanMiddle, longerPrefix, longerMiddle)
 }
 
-func (nodes *NodeManager) Search(query string) []string {
-	results := make([]string, 0)
-	nodes.MainNode.Search(&results, query)
+// match returns the subtrees matching word from the start of an address and
+// from the start of a later word
+func (nodes *NodeManager) match(word []rune) (prefix, middle []match) {
+	prefix = make([]match, 0)
+	nodes.MainNode.searchInternal(&prefix, word, 0, "")
 
+	middle = make([]match, 0)
 	for _, subNodes := range nodes.SubNodes {
-		if len(results) >= 5 {
-			break
-		}
-		subNodes.Search(&results, query)
+		subNodes.match(&middle, word)
+	}
+	return prefix, middle
+}
+
+// matchLot matches every spelling of a lot query, restricted to its main number
+func (nodes *NodeManager) matchLot(lot lotQuery, forms []string) (prefix, middle []match) {
+	for _, form := range forms {
+		formPrefix, formMiddle := nodes.match([]rune(form))
+		prefix = append(prefix, lot.restrict(formPrefix)...)
+		middle = append(middle, lot.restrict(formMiddle)...)
+	}
+	return prefix, middle
+}
+
+// SearchFuzzy returns up to limit addresses matching the query with at most
+// maxDistance jamo-level edits. Short queries get a smaller budget so that a
+// couple of keystrokes do not match everything, and the first jamo of the
+// matched word must be among the first maxDistance+1 jamo of the query.
+func (nodes *NodeManager) SearchFuzzy(query string, maxDistance int, limit int) []Result {
+	maxDistance = min(maxDistance, len(hangul.DecomposeString(query))/3)
+	if limit <= 0 || maxDistance <= 0 {
+		return make([]Result, 0)
+	}
+	matcher := newFuzzyMatcher(query, maxDistance)
+
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	prefix := make([]match, 0)
+	nodes.MainNode.matchFuzzy(&prefix, matcher)
+
+	middle := make([]match, 0)
+	for _, subNodes := range nodes.SubNodes {
+		subNodes.matchFuzzy(&middle, matcher)
 	}
 
-	return results
+	return rankMatches(limit, prefix, middle)
 }
@@ -0,0 +1,239 @@
+package trie
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func addresses(results []Result) []string {
+	found := make([]string, len(results))
+	for i, result := range results {
+		found[i] = result.Address
+	}
+	return found
+}
+
+// TestConcurrentAccess runs writers and readers against one trie; run it with
+// -race. Readers check that a moved address is always found exactly once.
+func TestConcurrentAccess(t *testing.T) {
+	const (
+		writers    = 4
+		readers    = 4
+		iterations = 200
+	)
+
+	nodes := CreateNodes()
+	for i := range 200 {
+		nodes.Insert(fmt.Sprintf("서울특별시 강남구 역삼동 %d", i), float32(i%7), &Payload{UniqueNo: fmt.Sprintf("base-%d", i)})
+	}
+
+	// Update와 Upsert로 옮겨 다니는 주소
+	updated := [2]string{"서울특별시 강남구 도곡동 1", "서울특별시 강남구 도곡동 2"}
+	moved := [2]string{"서울특별시 강남구 개포동 1", "서울특별시 강남구 개포동 2"}
+	nodes.Insert(updated[0], 1, &Payload{UniqueNo: "updated"})
+	nodes.Insert(moved[0], 1, &Payload{UniqueNo: "moved"})
+
+	var wg sync.WaitGroup
+
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range iterations {
+				address := fmt.Sprintf("서울특별시 서초구 writer%d동 %d", w, i)
+				uniqueNo := fmt.Sprintf("writer-%d-%d", w, i)
+				nodes.Insert(address, float32(i), &Payload{UniqueNo: uniqueNo})
+				if i%2 == 0 {
+					nodes.Delete(address)
+				} else if i%3 == 0 {
+					nodes.DeleteParcel(uniqueNo)
+				}
+			}
+		}()
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range iterations {
+			nodes.Update(updated[i%2], updated[(i+1)%2])
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := range iterations {
+			nodes.Upsert(moved[(i+1)%2], 1, &Payload{UniqueNo: "moved"})
+		}
+	}()
+
+	errs := make(chan error, readers)
+	for range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := range iterations {
+				for _, query := range []string{"서울특별시 강남구 도곡동", "서울특별시 강남구 개포동"} {
+					if results := nodes.Search(query, 10); len(results) != 1 {
+						errs <- fmt.Errorf("Search(%q) found %d addresses, want 1", query, len(results))
+						return
+					}
+				}
+				if address, ok := nodes.Parcel("moved"); !ok || !strings.HasPrefix(address, "서울특별시 강남구 개포동") {
+					errs <- fmt.Errorf("Parcel(moved) = %q, %v", address, ok)
+					return
+				}
+
+				nodes.Search("서울특별시 서초구", 20)
+				nodes.SearchTokens("역삼동 강남구 1", 20)
+
+				// 오래 걸리는 읽기는 가끔만 해서 쓰기가 계속 기다리지 않게 함
+				if round%20 == 0 {
+					nodes.SearchFuzzy("서울특별시 강남구 역삼둥", 1, 5)
+					nodes.Stats()
+					if err := nodes.WriteSnapshot(io.Discard); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if err := nodes.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	// 짝수 번째는 Delete, 3의 배수는 DeleteParcel로 지웠음
+	for w := range writers {
+		for i := range iterations {
+			address := fmt.Sprintf("서울특별시 서초구 writer%d동 %d", w, i)
+			_, indexed := nodes.Parcel(fmt.Sprintf("writer-%d-%d", w, i))
+			if want := i%2 != 0 && i%3 != 0; indexed != want {
+				t.Errorf("%s indexed = %v, want %v", address, indexed, want)
+			}
+		}
+	}
+	if results := nodes.Search(updated[iterations%2], 1); len(results) != 1 || results[0].Payload.UniqueNo != "updated" {
+		t.Errorf("updated address = %v", results)
+	}
+	if address, _ := nodes.Parcel("moved"); address != moved[iterations%2] {
+		t.Errorf("moved address = %q, want %q", address, moved[iterations%2])
+	}
+}
+
+func TestSearchDeduplicates(t *testing.T) {
+	nodes := CreateNodes()
+	// 강남구 강남대로 1은 앞부분과 두 번째 단어 모두에서 "강남"과 일치
+	nodes.Insert("강남구 강남대로 1", 3, nil)
+	nodes.Insert("강남구 역삼동 2", 2, nil)
+	nodes.Insert("서초구 강남대로 3", 1, nil)
+	nodes.Insert("서초구 서초동 강남 강남 4", 0, nil)
+
+	tests := []struct {
+		limit int
+		want  []string
+	}{
+		{3, []string{"강남구 강남대로 1", "강남구 역삼동 2", "서초구 강남대로 3"}},
+		{10, []string{"강남구 강남대로 1", "강남구 역삼동 2", "서초구 강남대로 3", "서초구 서초동 강남 강남 4"}},
+	}
+
+	for _, tt := range tests {
+		if got := addresses(nodes.Search("강남", tt.limit)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q, %d) = %q, want %q", "강남", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSearchChoseong(t *testing.T) {
+	nodes := CreateNodes()
+	nodes.Insert("서울특별시 강남구 역삼동 737", 2, nil)
+	nodes.Insert("서울특별시 서초구 서초동 12", 1, nil)
+	nodes.Insert("세종특별자치시 반곡동 1", 0, nil)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"ㅅㅇㅌㅂㅅ", []string{"서울특별시 강남구 역삼동 737", "서울특별시 서초구 서초동 12"}},
+		// 초성과 완성된 음절을 섞어서 입력
+		{"서ㅇ 강남", []string{"서울특별시 강남구 역삼동 737"}},
+		{"ㅅㅇㅌㅂㅅ ㅅㅊㄱ", []string{"서울특별시 서초구 서초동 12"}},
+		// 두 번째 단어부터 시작하는 초성
+		{"ㅂㄱㄷ", []string{"세종특별자치시 반곡동 1"}},
+		{"ㅅㅇㅌㅂㅈ", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := addresses(nodes.Search(tt.query, 10)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchPartialSyllable(t *testing.T) {
+	nodes := CreateNodes()
+	nodes.Insert("서울특별시 강남구 역삼동 737", 2, nil)
+	nodes.Insert("강원특별자치도 강릉시 강낭동 1", 1, nil)
+	nodes.Insert("서울특별시 강서구 화곡동 1", 0, nil)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// 마지막 글자가 조합 중이어도 자모 단위로 일치
+		{"강ㄴ", []string{"서울특별시 강남구 역삼동 737", "강원특별자치도 강릉시 강낭동 1"}},
+		{"강나", []string{"서울특별시 강남구 역삼동 737", "강원특별자치도 강릉시 강낭동 1"}},
+		{"강남", []string{"서울특별시 강남구 역삼동 737"}},
+		{"서울특별시 강ㅅ", []string{"서울특별시 강서구 화곡동 1"}},
+		{"강녀", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := addresses(nodes.Search(tt.query, 10)); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDeleteRemovesRefs(t *testing.T) {
+	nodes := CreateNodes()
+	for _, address := range []string{"서울특별시 강남구 역삼동 1", "서울특별시 강남구 역삼동 12", "서울특별시 서초구 서초동 1"} {
+		nodes.Insert(address, 0, nil)
+	}
+	refs := func() int {
+		total := 0
+		for _, count := range nodes.Stats().Refs {
+			total += count
+		}
+		return total
+	}
+	before := refs()
+
+	// 공백 뒤의 노드가 잘리지 않으면 참조는 그대로
+	nodes.Delete("서울특별시 강남구 역삼동 12")
+	if got := refs(); got != before {
+		t.Errorf("refs after deleting a lot sharing its word start = %d, want %d", got, before)
+	}
+
+	// 서초구 서초동 1을 지우면 서초구와 서초동을 가리키던 참조가 사라짐
+	nodes.Delete("서울특별시 서초구 서초동 1")
+	if got := refs(); got != before-2 {
+		t.Errorf("refs after deleting a branch = %d, want %d", got, before-2)
+	}
+	if err := nodes.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if got := addresses(nodes.Search("서초", 10)); len(got) != 0 {
+		t.Errorf("Search(%q) after delete = %q", "서초", got)
+	}
+}
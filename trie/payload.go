@@ -0,0 +1,18 @@
+package trie
+
+// Payload identifies the parcel an address belongs to
+type Payload struct {
+	UniqueNo string
+	FullCode string
+	Lat      float64
+	Lng      float64
+	// Counterpart is the other form of the same parcel's address: the
+	// road-name address of a jibun address and vice versa
+	Counterpart string
+}
+
+// Result is a single suggestion returned by a search
+type Result struct {
+	Address string
+	Payload *Payload
+}
@@ -0,0 +1,221 @@
+package trie
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// RadixNode is a node of a path-compressed trie. Label holds the runes of a
+// whole chain of single-child FullNodes. Labels never continue past a space,
+// so every word of an address starts at the beginning of some label.
+type RadixNode struct {
+	Label    string
+	Parent   *RadixNode
+	Children []*RadixNode
+	IsEnd    bool
+	Score    float32
+	MaxScore float32
+	Payload  *Payload
+}
+
+// RadixTree is an experiment measuring what path compression would save over
+// FullNode chains. It is used only by cmd/trie-bench and is not a replacement
+// for NodeManager: it covers exact and 초성 matching only, does not match an
+// unfinished last syllable at the jamo level ("강나" does not find "강남구"),
+// and has no lot number tiers, fuzzy or token search, deletes, snapshots or
+// locking. Searches may run concurrently only once inserts are done.
+type RadixTree struct {
+	Root RadixNode
+	// Refs[i] holds the nodes where word i+1 of some address begins, like JumpNode.Ref
+	Refs []map[*RadixNode]struct{}
+}
+
+func CreateRadixTree() *RadixTree {
+	return &RadixTree{Refs: make([]map[*RadixNode]struct{}, 0)}
+}
+
+// Insert adds an address with the score used to rank it among other suggestions
+// and an optional payload returned alongside it.
+func (tree *RadixTree) Insert(address string, score float32, payload *Payload) {
+	if len(address) == 0 {
+		return
+	}
+
+	maxDepth := jumpDepth(address)
+	for len(tree.Refs) < maxDepth {
+		tree.Refs = append(tree.Refs, make(map[*RadixNode]struct{}))
+	}
+
+	node := &tree.Root
+	rest := address
+	depth := 0
+
+	for {
+		node.MaxScore = max(node.MaxScore, score)
+
+		if rest == "" {
+			node.IsEnd = true
+			node.Score = score
+			node.Payload = payload
+			return
+		}
+
+		first, _ := utf8.DecodeRuneInString(rest)
+		index := node.childIndex(first)
+
+		var child *RadixNode
+		if index < len(node.Children) && node.Children[index].first() == first {
+			child = node.Children[index]
+		} else {
+			child = &RadixNode{Label: nextSegment(rest), Parent: node}
+			node.Children = slices.Insert(node.Children, index, child)
+		}
+
+		common := commonPrefixLength(child.Label, rest)
+		if common < len(child.Label) {
+			child.split(common)
+		}
+
+		// 공백 바로 뒤에서 시작하는 노드는 점프 참조로 등록
+		if depth > 0 && depth <= maxDepth && strings.HasSuffix(node.Label, " ") {
+			tree.Refs[depth-1][child] = struct{}{}
+		}
+
+		if strings.HasSuffix(child.Label, " ") {
+			depth++
+		}
+		rest = rest[len(child.Label):]
+		node = child
+	}
+}
+
+// childIndex returns the position of the first child whose label does not start before r.
+// Children are kept sorted by the first rune of their labels, which are all distinct.
+func (node *RadixNode) childIndex(r rune) int {
+	return sort.Search(len(node.Children), func(i int) bool {
+		return node.Children[i].first() >= r
+	})
+}
+
+func (node *RadixNode) first() rune {
+	r, _ := utf8.DecodeRuneInString(node.Label)
+	return r
+}
+
+// split keeps the first n bytes of the label in this node and moves the rest,
+// along with the children and the word ending here, into a new child. The node
+// itself stays where it is so jump references to it remain valid.
+func (node *RadixNode) split(n int) {
+	child := &RadixNode{
+		Label:    node.Label[n:],
+		Parent:   node,
+		Children: node.Children,
+		IsEnd:    node.IsEnd,
+		Score:    node.Score,
+		MaxScore: node.MaxScore,
+		Payload:  node.Payload,
+	}
+	for _, grandChild := range child.Children {
+		grandChild.Parent = child
+	}
+
+	node.Label = node.Label[:n]
+	node.Children = []*RadixNode{child}
+	node.IsEnd = false
+	node.Score = 0
+	node.Payload = nil
+}
+
+// nextSegment returns text up to and including its first space
+func nextSegment(text string) string {
+	if i := strings.IndexByte(text, ' '); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// commonPrefixLength returns the byte length of the longest common rune prefix
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) {
+		ra, size := utf8.DecodeRuneInString(a[n:])
+		rb, _ := utf8.DecodeRuneInString(b[n:])
+		if ra != rb {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// Search returns up to limit addresses matching the query, prefix matches first
+// and then matches that start after a space, each ranked by score.
+func (tree *RadixTree) Search(query string, limit int) []Result {
+	if limit <= 0 || query == "" {
+		return make([]Result, 0)
+	}
+	word := []rune(query)
+
+	prefix := make([]match, 0)
+	tree.Root.searchInternal(&prefix, word, "")
+
+	middle := make([]match, 0)
+	for _, refs := range tree.Refs {
+		for ref := range refs {
+			if matchRune(word[0], ref.first()) {
+				ref.searchInternal(&middle, word, ref.Parent.path())
+			}
+		}
+	}
+
+	return rankMatches(limit, prefix, middle)
+}
+
+// searchInternal matches word against the label and continues into the children
+// with whatever is left. result is the text from the root up to the parent.
+func (node *RadixNode) searchInternal(matches *[]match, word []rune, result string) {
+	consumed := 0
+	for _, r := range node.Label {
+		if consumed == len(word) {
+			break
+		}
+		if !matchRune(word[consumed], r) {
+			return
+		}
+		consumed++
+	}
+
+	result += node.Label
+	if consumed == len(word) {
+		*matches = append(*matches, match{node: node, word: result})
+		return
+	}
+
+	for _, child := range node.Children {
+		child.searchInternal(matches, word[consumed:], result)
+	}
+}
+
+// path returns the text from the root up to and including this node
+func (node *RadixNode) path() string {
+	if node.Parent == nil {
+		return node.Label
+	}
+	return node.Parent.path() + node.Label
+}
+
+func (node *RadixNode) bestScore() float32 {
+	return node.MaxScore
+}
+
+func (node *RadixNode) end() (bool, float32, *Payload) {
+	return node.IsEnd, node.Score, node.Payload
+}
+
+func (node *RadixNode) expand(push func(child rankNode, label string)) {
+	for _, child := range node.Children {
+		push(child, child.Label)
+	}
+}
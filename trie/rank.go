@@ -0,0 +1,137 @@
+package trie
+
+import "container/heap"
+
+// rankNode is a node of any trie in this package that can be ranked
+type rankNode interface {
+	// bestScore is the highest score of any word in the subtree
+	bestScore() float32
+	// end reports whether a word ends at the node, with its score and payload
+	end() (bool, float32, *Payload)
+	// expand calls push for every child with the text the child adds to the word
+	expand(push func(child rankNode, label string))
+}
+
+// match is a node whose whole subtree satisfies a query, word being the text
+// from the root up to and including the node.
+type match struct {
+	node rankNode
+	word string
+}
+
+// rankEntry is either a whole subtree keyed by its best score or a single word
+// keyed by its own score. Popping entries in order yields words best first.
+type rankEntry struct {
+	node     rankNode
+	word     string
+	tier     int
+	score    float32
+	terminal bool
+}
+
+type rankQueue []rankEntry
+
+func (q rankQueue) Len() int { return len(q) }
+
+func (q rankQueue) Less(i, j int) bool {
+	if q[i].tier != q[j].tier {
+		return q[i].tier < q[j].tier
+	}
+	if q[i].score != q[j].score {
+		return q[i].score > q[j].score
+	}
+	// 점수가 같으면 숫자는 크기순으로 사전순, 같은 위치라면 단어가 하위 트리보다 먼저
+	if c := compareNatural(q[i].word, q[j].word); c != 0 {
+		return c < 0
+	}
+	return q[i].terminal && !q[j].terminal
+}
+
+func (q rankQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *rankQueue) Push(x any) { *q = append(*q, x.(rankEntry)) }
+
+func (q *rankQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// rankMatches returns up to limit distinct results from the matched subtrees,
+// ordered by tier (the index of the slice the match came from), then score,
+// then text with numbers in numeric order. Subtrees are expanded lazily, so
+// only the branches that can still beat the current best are visited. A word
+// reached through several matches, e.g. as a prefix and again after a space,
+// is returned once in its best tier.
+func rankMatches(limit int, tiers ...[]match) []Result {
+	results := make([]Result, 0)
+	if limit <= 0 {
+		return results
+	}
+
+	queue := make(rankQueue, 0)
+	for tier, matches := range tiers {
+		for _, m := range matches {
+			queue = append(queue, rankEntry{node: m.node, word: m.word, tier: tier, score: m.node.bestScore()})
+		}
+	}
+	heap.Init(&queue)
+
+	// 같은 노드가 여러 경로로 들어와도 처음 꺼낸 것만 사용
+	expanded := make(map[rankNode]struct{})
+	returned := make(map[rankNode]struct{})
+
+	for queue.Len() > 0 && len(results) < limit {
+		entry := heap.Pop(&queue).(rankEntry)
+
+		isEnd, score, payload := entry.node.end()
+
+		if entry.terminal {
+			node := identity(entry.node)
+			if _, ok := returned[node]; ok {
+				continue
+			}
+			returned[node] = struct{}{}
+			results = append(results, Result{Address: entry.word, Payload: payload})
+			continue
+		}
+
+		if _, ok := expanded[entry.node]; ok {
+			continue
+		}
+		expanded[entry.node] = struct{}{}
+
+		if isEnd {
+			heap.Push(&queue, rankEntry{node: entry.node, word: entry.word, tier: entry.tier, score: score, terminal: true})
+		}
+		entry.node.expand(func(child rankNode, label string) {
+			heap.Push(&queue, rankEntry{node: child, word: entry.word + label, tier: entry.tier, score: child.bestScore()})
+		})
+	}
+
+	return results
+}
+
+// identity returns the node a rankNode stands for, so a wordNode and its
+// FullNode count as the same word
+func identity(node rankNode) rankNode {
+	if word, ok := node.(wordNode); ok {
+		return word.FullNode
+	}
+	return node
+}
+
+func (node *FullNode) bestScore() float32 {
+	return node.MaxScore
+}
+
+func (node *FullNode) end() (bool, float32, *Payload) {
+	return node.IsEnd, node.Score, node.Payload
+}
+
+func (node *FullNode) expand(push func(child rankNode, label string)) {
+	for _, child := range node.Children {
+		push(child, string(child.Value))
+	}
+}
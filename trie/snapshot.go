@@ -0,0 +1,428 @@
+package trie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// 스냅샷 형식
+//
+//	magic "IZTR" | version uint16 | trie count | (jump tables | nodes) * count | crc32
+//
+// version: 형식 버전, 다른 버전의 스냅샷은 읽지 않음
+// trie count: 한 파일에 담긴 트라이 수
+// jump tables: 테이블 수, 각 테이블의 참조 수와 참조 노드의 전위 순회 번호
+// nodes: 루트부터 전위 순회한 노드 (값, 플래그, 점수, 페이로드, 자식 수)
+// payload: 고유번호, 법정동 코드, 위도, 경도, 대응 주소(Counterpart)
+// crc32: 앞의 모든 바이트에 대한 IEEE 체크섬 (little endian)
+const (
+	snapshotMagic = "IZTR"
+	// snapshotVersion is the only format version read and written. Earlier
+	// versions held a single trie without counterparts and are rejected.
+	snapshotVersion = 3
+
+	flagEnd     = 1 << 0
+	flagPayload = 1 << 1
+)
+
+// ErrSnapshotChecksum is returned when a snapshot does not match its checksum
+var ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+
+// WriteSnapshot encodes the trie, including the jump references, to w
+func (nodes *NodeManager) WriteSnapshot(w io.Writer) error {
+	return WriteSnapshots(w, nodes)
+}
+
+// WriteSnapshots encodes several tries to w as one snapshot under a single
+// checksum, so they are always read back together
+func WriteSnapshots(w io.Writer, tries ...*NodeManager) error {
+	out := &snapshotWriter{w: bufio.NewWriter(w), crc: crc32.NewIEEE()}
+
+	out.bytes([]byte(snapshotMagic))
+	out.bytes(binary.LittleEndian.AppendUint16(nil, snapshotVersion))
+	out.uvarint(uint64(len(tries)))
+	for _, nodes := range tries {
+		nodes.writeSnapshot(out)
+	}
+
+	if out.err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", out.err)
+	}
+
+	// 체크섬은 자기 자신을 포함하지 않으므로 직접 기록
+	if _, err := out.w.Write(binary.LittleEndian.AppendUint32(nil, out.crc.Sum32())); err != nil {
+		return fmt.Errorf("failed to write snapshot checksum: %w", err)
+	}
+	return out.w.Flush()
+}
+
+// writeSnapshot encodes the jump tables and nodes of the trie
+func (nodes *NodeManager) writeSnapshot(out *snapshotWriter) {
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	// 참조 노드의 전위 순회 번호를 먼저 계산
+	indexes := make(map[*FullNode]uint64)
+	for _, subNode := range nodes.SubNodes {
+		for _, ref := range subNode.Ref {
+			indexes[ref] = 0
+		}
+	}
+	var next uint64
+	nodes.MainNode.walk(func(node *FullNode) {
+		if _, ok := indexes[node]; ok {
+			indexes[node] = next
+		}
+		next++
+	})
+
+	out.uvarint(uint64(len(nodes.SubNodes)))
+	for _, subNode := range nodes.SubNodes {
+		out.uvarint(uint64(len(subNode.Ref)))
+		for _, ref := range subNode.Ref {
+			out.uvarint(indexes[ref])
+		}
+	}
+
+	nodes.MainNode.writeSnapshot(out)
+}
+
+func (node *FullNode) walk(visit func(*FullNode)) {
+	visit(node)
+	for _, child := range node.Children {
+		child.walk(visit)
+	}
+}
+
+func (node *FullNode) writeSnapshot(out *snapshotWriter) {
+	var flags byte
+	if node.IsEnd {
+		flags |= flagEnd
+	}
+	if node.Payload != nil {
+		flags |= flagPayload
+	}
+
+	out.uvarint(uint64(node.Value))
+	out.bytes([]byte{flags})
+	if node.IsEnd {
+		out.uvarint(uint64(math.Float32bits(node.Score)))
+	}
+	if node.Payload != nil {
+		out.string(node.Payload.UniqueNo)
+		out.string(node.Payload.FullCode)
+		out.float64(node.Payload.Lat)
+		out.float64(node.Payload.Lng)
+		out.string(node.Payload.Counterpart)
+	}
+
+	out.uvarint(uint64(len(node.Children)))
+	for _, child := range node.Children {
+		child.writeSnapshot(out)
+	}
+}
+
+// ReadSnapshot decodes a trie written by WriteSnapshot, verifying its version and checksum
+func ReadSnapshot(r io.Reader) (*NodeManager, error) {
+	tries, err := ReadSnapshots(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(tries) != 1 {
+		return nil, fmt.Errorf("snapshot holds %d tries, want 1", len(tries))
+	}
+	return tries[0], nil
+}
+
+// ReadSnapshots decodes the tries written by WriteSnapshots in the same order,
+// verifying the version and checksum
+func ReadSnapshots(r io.Reader) ([]*NodeManager, error) {
+	in := &snapshotReader{r: bufio.NewReader(r), crc: crc32.NewIEEE()}
+
+	magic := in.bytes(len(snapshotMagic))
+	if in.err == nil && string(magic) != snapshotMagic {
+		return nil, errors.New("not a trie snapshot")
+	}
+	if version := in.bytes(2); in.err == nil && binary.LittleEndian.Uint16(version) != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d, want %d", binary.LittleEndian.Uint16(version), snapshotVersion)
+	}
+
+	count := in.length()
+	if in.err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", in.err)
+	}
+
+	decoded := make([]*decodedTrie, 0)
+	for range count {
+		trie := in.trie()
+		if in.err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", in.err)
+		}
+		decoded = append(decoded, trie)
+	}
+
+	sum := in.crc.Sum32()
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(in.r, trailer); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot checksum: %w", err)
+	}
+	if binary.LittleEndian.Uint32(trailer) != sum {
+		return nil, ErrSnapshotChecksum
+	}
+
+	// 체크섬을 확인한 뒤에 점프 참조를 연결
+	tries := make([]*NodeManager, len(decoded))
+	for i, trie := range decoded {
+		for _, table := range trie.tables {
+			jumpNode := CreateJumpNode()
+			for _, index := range table {
+				ref := trie.wanted[index]
+				if ref == nil {
+					return nil, fmt.Errorf("snapshot references missing node %d", index)
+				}
+				jumpNode.Ref = append(jumpNode.Ref, ref)
+			}
+			trie.nodes.SubNodes = append(trie.nodes.SubNodes, jumpNode)
+		}
+		trie.nodes.reindex()
+		tries[i] = trie.nodes
+	}
+	return tries, nil
+}
+
+// decodedTrie is a trie read from a snapshot whose jump references are not
+// linked yet
+type decodedTrie struct {
+	nodes  *NodeManager
+	tables [][]uint64
+	// wanted maps the preorder number of each referenced node to the node
+	wanted map[uint64]*FullNode
+}
+
+func (in *snapshotReader) trie() *decodedTrie {
+	// 참조 번호를 먼저 읽어 두고 노드를 복원하면서 연결
+	// 길이는 체크섬 확인 전이므로 미리 할당하지 않고 읽은 만큼만 늘림
+	trie := &decodedTrie{nodes: CreateNodes(), wanted: make(map[uint64]*FullNode)}
+	tables := in.length()
+	for range tables {
+		if in.err != nil {
+			return nil
+		}
+		refs := in.length()
+		table := make([]uint64, 0)
+		for range refs {
+			index := in.uvarint()
+			if in.err != nil {
+				return nil
+			}
+			table = append(table, index)
+			trie.wanted[index] = nil
+		}
+		trie.tables = append(trie.tables, table)
+	}
+	if in.err != nil {
+		return nil
+	}
+
+	var next uint64
+	in.node(&trie.nodes.MainNode, &next, trie.wanted)
+	return trie
+}
+
+func (in *snapshotReader) node(node *FullNode, next *uint64, wanted map[uint64]*FullNode) {
+	if _, ok := wanted[*next]; ok {
+		wanted[*next] = node
+	}
+	*next++
+
+	node.Value = rune(in.uvarint())
+	flags := in.bytes(1)
+	if in.err != nil {
+		return
+	}
+	if flags[0]&flagEnd != 0 {
+		node.IsEnd = true
+		node.Score = math.Float32frombits(uint32(in.uvarint()))
+		node.MaxScore = node.Score
+	}
+	if flags[0]&flagPayload != 0 {
+		node.Payload = &Payload{UniqueNo: in.string(), FullCode: in.string()}
+		node.Payload.Lat = in.float64()
+		node.Payload.Lng = in.float64()
+		node.Payload.Counterpart = in.string()
+	}
+
+	count := in.length()
+	for range count {
+		if in.err != nil {
+			return
+		}
+		child := &FullNode{Parent: node}
+		in.node(child, next, wanted)
+		node.Children = append(node.Children, child)
+		node.MaxScore = max(node.MaxScore, child.MaxScore)
+	}
+}
+
+// SaveSnapshot writes the trie to path, replacing it only once the file is complete
+func (nodes *NodeManager) SaveSnapshot(path string) error {
+	return SaveSnapshots(path, nodes)
+}
+
+// SaveSnapshots writes several tries to one file at path, replacing it only
+// once the file is complete
+func SaveSnapshots(path string, tries ...*NodeManager) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := WriteSnapshots(file, tries...); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to move snapshot into place: %w", err)
+	}
+	return nil
+}
+
+// LoadSnapshot reads a trie from a file written by SaveSnapshot
+func LoadSnapshot(path string) (*NodeManager, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadSnapshot(file)
+}
+
+// LoadSnapshots reads the tries from a file written by SaveSnapshots
+func LoadSnapshots(path string) ([]*NodeManager, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadSnapshots(file)
+}
+
+// snapshotWriter keeps the first error and the running checksum
+type snapshotWriter struct {
+	w   *bufio.Writer
+	crc hash.Hash32
+	err error
+}
+
+func (out *snapshotWriter) bytes(b []byte) {
+	if out.err != nil {
+		return
+	}
+	out.crc.Write(b)
+	_, out.err = out.w.Write(b)
+}
+
+func (out *snapshotWriter) uvarint(v uint64) {
+	out.bytes(binary.AppendUvarint(nil, v))
+}
+
+func (out *snapshotWriter) float64(v float64) {
+	out.bytes(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+func (out *snapshotWriter) string(s string) {
+	out.uvarint(uint64(len(s)))
+	out.bytes([]byte(s))
+}
+
+// snapshotReader keeps the first error and the running checksum
+type snapshotReader struct {
+	r   *bufio.Reader
+	crc hash.Hash32
+	err error
+}
+
+// maxSnapshotLength rejects corrupt length fields early; nothing is allocated
+// from a length beyond snapshotChunk before it is actually read
+const (
+	maxSnapshotLength = 1 << 28
+	snapshotChunk     = 4096
+)
+
+func (in *snapshotReader) ReadByte() (byte, error) {
+	b, err := in.r.ReadByte()
+	if err == nil {
+		in.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (in *snapshotReader) bytes(n int) []byte {
+	if in.err != nil {
+		return nil
+	}
+
+	var b []byte
+	if n <= snapshotChunk {
+		b = make([]byte, n)
+		_, in.err = io.ReadFull(in.r, b)
+	} else {
+		// 긴 문자열은 손상된 길이만큼 미리 할당하지 않도록 읽은 만큼만 늘림
+		b, in.err = io.ReadAll(io.LimitReader(in.r, int64(n)))
+		if in.err == nil && len(b) < n {
+			in.err = io.ErrUnexpectedEOF
+		}
+	}
+	if in.err != nil {
+		return nil
+	}
+	in.crc.Write(b)
+	return b
+}
+
+func (in *snapshotReader) uvarint() uint64 {
+	if in.err != nil {
+		return 0
+	}
+	var v uint64
+	v, in.err = binary.ReadUvarint(in)
+	return v
+}
+
+func (in *snapshotReader) length() int {
+	n := in.uvarint()
+	if in.err == nil && n > maxSnapshotLength {
+		in.err = fmt.Errorf("invalid length %d", n)
+	}
+	if in.err != nil {
+		return 0
+	}
+	return int(n)
+}
+
+func (in *snapshotReader) float64() float64 {
+	b := in.bytes(8)
+	if b == nil {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (in *snapshotReader) string() string {
+	return string(in.bytes(in.length()))
+}
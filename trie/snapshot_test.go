@@ -0,0 +1,188 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newSnapshotTrie() *NodeManager {
+	nodes := CreateNodes()
+	nodes.Insert("서울특별시 강남구 역삼동 737", 3, &Payload{
+		UniqueNo: "1168010100107370000", FullCode: "1168010100", Lat: 37.5, Lng: 127.03,
+		Counterpart: "서울특별시 강남구 테헤란로 152",
+	})
+	nodes.Insert("서울특별시 강남구 역삼동 산 12-3", 1, &Payload{UniqueNo: "1168010100200120003"})
+	nodes.Insert("서울특별시 강남구 삼성동 1", 2, nil)
+	nodes.Insert("세종특별자치시 반곡동 1", 0, &Payload{UniqueNo: "3611010100100010000"})
+	return nodes
+}
+
+func encodeSnapshot(t *testing.T, tries ...*NodeManager) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := WriteSnapshots(&buf, tries...); err != nil {
+		t.Fatalf("WriteSnapshots() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// assertSameTrie checks that loaded answers the queries like nodes
+func assertSameTrie(t *testing.T, loaded, nodes *NodeManager) {
+	t.Helper()
+
+	if err := loaded.Validate(); err != nil {
+		t.Fatalf("loaded trie is invalid: %v", err)
+	}
+	if got, want := loaded.Stats(), nodes.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	for _, query := range []string{"서울", "ㅇㅅㄷ", "역삼동 737", "역삼동 산12", "강남구 역삼", "반곡동"} {
+		got, want := loaded.Search(query, 10), nodes.Search(query, 10)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q) = %v, want %v", query, got, want)
+		}
+	}
+	if got, want := loaded.SearchTokens("역삼동 강남구", 10), nodes.SearchTokens("역삼동 강남구", 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("SearchTokens() = %v, want %v", got, want)
+	}
+	if address, ok := loaded.Parcel("1168010100107370000"); !ok || address != "서울특별시 강남구 역삼동 737" {
+		t.Errorf("Parcel() = %q, %v", address, ok)
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	nodes := newSnapshotTrie()
+
+	loaded, err := ReadSnapshot(bytes.NewReader(encodeSnapshot(t, nodes)))
+	if err != nil {
+		t.Fatalf("ReadSnapshot() error = %v", err)
+	}
+	assertSameTrie(t, loaded, nodes)
+}
+
+func TestSnapshotRoundTripSeveralTries(t *testing.T) {
+	jibun := newSnapshotTrie()
+	road := CreateNodes()
+	road.Insert("서울특별시 강남구 테헤란로 152", 3, &Payload{UniqueNo: "1168010100107370000", Counterpart: "서울특별시 강남구 역삼동 737"})
+
+	data := encodeSnapshot(t, jibun, road)
+	tries, err := ReadSnapshots(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadSnapshots() error = %v", err)
+	}
+	if len(tries) != 2 {
+		t.Fatalf("ReadSnapshots() returned %d tries, want 2", len(tries))
+	}
+	assertSameTrie(t, tries[0], jibun)
+	if got, want := tries[1].Search("테헤란로", 10), road.Search("테헤란로", 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("road Search() = %v, want %v", got, want)
+	}
+
+	if _, err := ReadSnapshot(bytes.NewReader(data)); err == nil {
+		t.Error("ReadSnapshot() of a snapshot with two tries succeeded")
+	}
+}
+
+func TestSnapshotCorruption(t *testing.T) {
+	data := encodeSnapshot(t, newSnapshotTrie())
+
+	corrupt := func(modify func([]byte)) []byte {
+		corrupted := slices.Clone(data)
+		modify(corrupted)
+		return corrupted
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "changed payload",
+			data: corrupt(func(b []byte) {
+				b[bytes.Index(b, []byte("1168010100107370000"))] = '2'
+			}),
+			wantErr: ErrSnapshotChecksum,
+		},
+		{
+			name:    "changed checksum",
+			data:    corrupt(func(b []byte) { b[len(b)-1] ^= 0xff }),
+			wantErr: ErrSnapshotChecksum,
+		},
+		{
+			name:    "bad magic",
+			data:    corrupt(func(b []byte) { copy(b, "ABCD") }),
+			wantMsg: "not a trie snapshot",
+		},
+		{
+			name:    "newer version",
+			data:    corrupt(func(b []byte) { binary.LittleEndian.PutUint16(b[len(snapshotMagic):], snapshotVersion+1) }),
+			wantMsg: "unsupported snapshot version",
+		},
+		{
+			name:    "older version",
+			data:    corrupt(func(b []byte) { binary.LittleEndian.PutUint16(b[len(snapshotMagic):], snapshotVersion-1) }),
+			wantMsg: "unsupported snapshot version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSnapshot(bytes.NewReader(tt.data))
+			if err == nil {
+				t.Fatal("ReadSnapshot() succeeded")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadSnapshot() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("ReadSnapshot() error = %v, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestSnapshotTruncated(t *testing.T) {
+	data := encodeSnapshot(t, newSnapshotTrie())
+
+	for length := range len(data) {
+		if _, err := ReadSnapshot(bytes.NewReader(data[:length])); err == nil {
+			t.Errorf("ReadSnapshot() of the first %d of %d bytes succeeded", length, len(data))
+		}
+	}
+}
+
+func TestSnapshotHugeLength(t *testing.T) {
+	// 체크섬 확인 전의 길이만 보고 큰 메모리를 잡지 않아야 함
+	prefix := binary.LittleEndian.AppendUint16([]byte(snapshotMagic), snapshotVersion)
+	header := binary.AppendUvarint(slices.Clone(prefix), 1)
+
+	tests := map[string][]byte{
+		"trie count":   binary.AppendUvarint(prefix, maxSnapshotLength),
+		"table count":  binary.AppendUvarint(slices.Clone(header), maxSnapshotLength),
+		"table length": binary.AppendUvarint(binary.AppendUvarint(header, 1), maxSnapshotLength),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err := ReadSnapshot(bytes.NewReader(data))
+			runtime.ReadMemStats(&after)
+
+			if err == nil {
+				t.Fatal("ReadSnapshot() succeeded")
+			}
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+				t.Errorf("ReadSnapshot() allocated %d bytes", allocated)
+			}
+		})
+	}
+}
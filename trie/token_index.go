@@ -0,0 +1,385 @@
+package trie
+
+import (
+	"container/heap"
+	"gin-project/hangul"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// tokenNode is a node of the token dictionary, a rune trie holding every
+// space separated token of the indexed addresses. The node where a token ends
+// lists the ids of the words containing it.
+type tokenNode struct {
+	value  rune
+	parent *tokenNode
+	// children is kept sorted by value like FullNode.Children
+	children []*tokenNode
+	// postings holds the ids of the words containing the token ending here,
+	// in increasing order. Deleted words stay listed until compacted.
+	postings []uint32
+	// stale is the number of deleted words still in postings
+	stale int
+	// count is the number of postings in the subtree, stale ones included
+	count int
+}
+
+// addTokens gives a newly indexed word its id and adds it to the postings of
+// every token of address
+func (nodes *NodeManager) addTokens(address string, word *FullNode) {
+	word.id = uint32(len(nodes.words))
+	nodes.words = append(nodes.words, word)
+
+	for _, token := range addressTokens(address) {
+		node := &nodes.tokens
+		for _, r := range token {
+			node = node.childOrInsert(r)
+		}
+		// id는 계속 증가하므로 뒤에 붙이면 정렬이 유지됨
+		node.postings = append(node.postings, word.id)
+		node.addCount(1)
+	}
+}
+
+// removeTokens marks a word that is no longer indexed as deleted. Its id stays
+// in the postings of its tokens, which are compacted once half of their ids
+// are stale, so deleting stays cheap even for tokens most addresses contain.
+func (nodes *NodeManager) removeTokens(address string, word *FullNode) {
+	nodes.words[word.id] = nil
+
+	for _, token := range addressTokens(address) {
+		node := nodes.tokens.find(token)
+		if node == nil {
+			continue
+		}
+		node.stale++
+		if node.stale*2 < len(node.postings) {
+			continue
+		}
+
+		// 지워진 id를 걸러내고 아무 주소에도 없는 토큰은 사전에서 제거
+		live := slices.DeleteFunc(node.postings, func(id uint32) bool {
+			return nodes.words[id] == nil
+		})
+		node.addCount(len(live) - len(node.postings))
+		node.postings = slices.Clip(live)
+		node.stale = 0
+		node.prune()
+	}
+}
+
+// reindexTokens rebuilds the token index from the words in the trie
+func (nodes *NodeManager) reindexTokens() {
+	nodes.tokens = tokenNode{}
+	// id 0은 쓰지 않음
+	nodes.words = []*FullNode{nil}
+	nodes.MainNode.walk(func(node *FullNode) {
+		if node.IsEnd {
+			nodes.addTokens(node.combineParentsInternal(""), node)
+		}
+	})
+}
+
+// addCount adds delta to the posting count of the node and its ancestors
+func (node *tokenNode) addCount(delta int) {
+	for current := node; current != nil; current = current.parent {
+		current.count += delta
+	}
+}
+
+// addressTokens returns the distinct space separated tokens of an address
+func addressTokens(address string) []string {
+	tokens := strings.Fields(address)
+	unique := tokens[:0]
+	for _, token := range tokens {
+		if !slices.Contains(unique, token) {
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
+
+// childIndex returns the position of the first child whose value is not less than r
+func (node *tokenNode) childIndex(r rune) int {
+	return sort.Search(len(node.children), func(i int) bool {
+		return node.children[i].value >= r
+	})
+}
+
+func (node *tokenNode) child(r rune) *tokenNode {
+	index := node.childIndex(r)
+	if index < len(node.children) && node.children[index].value == r {
+		return node.children[index]
+	}
+	return nil
+}
+
+func (node *tokenNode) childOrInsert(r rune) *tokenNode {
+	index := node.childIndex(r)
+	if index < len(node.children) && node.children[index].value == r {
+		return node.children[index]
+	}
+	child := &tokenNode{value: r, parent: node}
+	node.children = slices.Insert(node.children, index, child)
+	return child
+}
+
+// childrenBetween returns the children whose values lie in [first, last]
+func (node *tokenNode) childrenBetween(first, last rune) []*tokenNode {
+	return node.children[node.childIndex(first):node.childIndex(last+1)]
+}
+
+// find returns the node where token ends, or nil
+func (node *tokenNode) find(token string) *tokenNode {
+	for _, r := range token {
+		if node = node.child(r); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// prune unlinks the node and every ancestor left without postings or children
+func (node *tokenNode) prune() {
+	for current := node; current.parent != nil && len(current.postings) == 0 && len(current.children) == 0; {
+		parent := current.parent
+		index := parent.childIndex(current.value)
+		parent.children = slices.Delete(parent.children, index, index+1)
+		current = parent
+	}
+}
+
+// walk calls visit for the node and every node below it
+func (node *tokenNode) walk(visit func(*tokenNode)) {
+	visit(node)
+	for _, child := range node.children {
+		child.walk(visit)
+	}
+}
+
+// matchPrefix collects the nodes whose every token starts with prefix under
+// the same rules as the trie search: 초성 match whole syllables and the last
+// rune may be an unfinished syllable.
+func (node *tokenNode) matchPrefix(matched *[]*tokenNode, prefix []rune) {
+	if len(prefix) == 0 {
+		*matched = append(*matched, node)
+		return
+	}
+
+	r := prefix[0]
+	first, last, isChoseong := hangul.ChoseongRange(r)
+	if child := node.child(r); child != nil {
+		child.matchPrefix(matched, prefix[1:])
+	}
+	if isChoseong {
+		for _, child := range node.childrenBetween(first, last) {
+			child.matchPrefix(matched, prefix[1:])
+		}
+	}
+
+	if len(prefix) != 1 {
+		return
+	}
+
+	// 마지막 글자는 조합 중일 수 있으므로 자모 단위로 비교
+	keys := hangul.Decompose(r)
+	first, last, ok := hangul.ChoseongRange(keys[0])
+	if !ok {
+		return
+	}
+	for _, child := range node.childrenBetween(first, last) {
+		if !matchRune(r, child.value) {
+			child.matchPartial(matched, keys)
+		}
+	}
+}
+
+// matchPartial matches the jamo keys of an unfinished syllable like
+// FullNode.searchPartial
+func (node *tokenNode) matchPartial(matched *[]*tokenNode, keys []rune) {
+	value := hangul.Decompose(node.value)
+
+	if hasRunePrefix(value, keys) {
+		*matched = append(*matched, node)
+		return
+	}
+
+	if len(keys) <= len(value) || !hasRunePrefix(keys, value) {
+		return
+	}
+
+	rest := keys[len(value):]
+	first, last, ok := hangul.ChoseongRange(rest[0])
+	if !ok {
+		return
+	}
+	for _, child := range node.childrenBetween(first, last) {
+		child.matchPartial(matched, rest)
+	}
+}
+
+// tokenPostings is the set of words that may contain a query token: the union
+// of the postings of some tokens and of every token in some subtrees
+type tokenPostings struct {
+	lists    [][]uint32
+	subtrees []*tokenNode
+	// count is the total number of postings, counting a word once per token
+	count int
+	// positions remember how far contains has advanced in each list
+	positions []int
+}
+
+// lookup returns the postings of the words with a token that a query token
+// may match. A lot number matches that exact main number and its sub numbers,
+// with or without 산 (12 matches 12, 12-3 and 산12), other tokens match as
+// prefixes. The final check is left to matchTokens.
+func (nodes *NodeManager) lookup(token string) *tokenPostings {
+	postings := &tokenPostings{}
+
+	lot, isLot := parseLotQuery(token)
+	if !isLot {
+		matched := make([]*tokenNode, 0)
+		nodes.tokens.matchPrefix(&matched, []rune(token))
+		for _, node := range matched {
+			postings.addSubtree(node)
+		}
+		return postings
+	}
+
+	for _, number := range []string{lot.number, string(mountainPrefix) + lot.number} {
+		if strings.Contains(lot.number, "-") {
+			if node := nodes.tokens.find(number); node != nil {
+				postings.addSubtree(node)
+			}
+			continue
+		}
+		if node := nodes.tokens.find(number); node != nil && len(node.postings) > 0 {
+			postings.lists = append(postings.lists, node.postings)
+			postings.count += len(node.postings)
+		}
+		if node := nodes.tokens.find(number + "-"); node != nil {
+			postings.addSubtree(node)
+		}
+	}
+	return postings
+}
+
+func (postings *tokenPostings) addSubtree(node *tokenNode) {
+	postings.subtrees = append(postings.subtrees, node)
+	postings.count += node.count
+}
+
+// expand turns the subtrees into posting lists, giving up once there would be
+// more than maxLists lists (maxLists < 0 means no limit)
+func (postings *tokenPostings) expand(maxLists int) bool {
+	lists := postings.lists
+	for _, subtree := range postings.subtrees {
+		if !subtree.collect(&lists, maxLists) {
+			return false
+		}
+	}
+	postings.lists = lists
+	postings.subtrees = nil
+	return true
+}
+
+// collect appends the non-empty posting lists of the subtree to lists,
+// stopping with false once there are more than maxLists of them
+func (node *tokenNode) collect(lists *[][]uint32, maxLists int) bool {
+	if len(node.postings) > 0 {
+		if maxLists >= 0 && len(*lists) >= maxLists {
+			return false
+		}
+		*lists = append(*lists, node.postings)
+	}
+	for _, child := range node.children {
+		if !child.collect(lists, maxLists) {
+			return false
+		}
+	}
+	return true
+}
+
+// each calls visit for every id in the expanded postings once, in increasing
+// order
+func (postings *tokenPostings) each(visit func(id uint32)) {
+	if len(postings.lists) == 1 {
+		for _, id := range postings.lists[0] {
+			visit(id)
+		}
+		return
+	}
+
+	// 목록마다 다음 위치를 두고 가장 작은 id부터 꺼냄
+	queue := make(postingQueue, 0, len(postings.lists))
+	for _, list := range postings.lists {
+		queue = append(queue, list)
+	}
+	heap.Init(&queue)
+
+	previous := uint32(0)
+	for queue.Len() > 0 {
+		id := queue[0][0]
+		if queue[0] = queue[0][1:]; len(queue[0]) == 0 {
+			heap.Pop(&queue)
+		} else {
+			heap.Fix(&queue, 0)
+		}
+
+		// 한 주소가 여러 토큰으로 일치하면 여러 목록에 들어 있음
+		if id != previous {
+			visit(id)
+			previous = id
+		}
+	}
+}
+
+// postingQueue orders the unvisited rest of posting lists by their first id
+type postingQueue [][]uint32
+
+func (q postingQueue) Len() int           { return len(q) }
+func (q postingQueue) Less(i, j int) bool { return q[i][0] < q[j][0] }
+func (q postingQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *postingQueue) Push(x any) { *q = append(*q, x.([]uint32)) }
+
+func (q *postingQueue) Pop() any {
+	old := *q
+	list := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return list
+}
+
+// contains reports whether id is in the expanded postings. Ids must be asked
+// for in increasing order, so each list is searched only past the last
+// position.
+func (postings *tokenPostings) contains(id uint32) bool {
+	if postings.positions == nil {
+		postings.positions = make([]int, len(postings.lists))
+	}
+
+	found := false
+	for i, list := range postings.lists {
+		position := gallop(list, postings.positions[i], id)
+		postings.positions[i] = position
+		if position < len(list) && list[position] == id {
+			found = true
+		}
+	}
+	return found
+}
+
+// gallop returns the first position from start whose id is not less than id,
+// doubling the step before searching so nearby positions are found quickly
+func gallop(list []uint32, start int, id uint32) int {
+	end := start
+	for step := 1; end < len(list) && list[end] < id; step *= 2 {
+		start = end + 1
+		end += step
+	}
+	end = min(end, len(list))
+	return start + sort.Search(end-start, func(j int) bool {
+		return list[start+j] >= id
+	})
+}
@@ -0,0 +1,290 @@
+package trie
+
+import (
+	"container/heap"
+	"gin-project/hangul"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxTokenPostings is the most postings the most selective query token
+	// may have, which also bounds how many addresses are checked on their
+	// text; vaguer queries are left to the other searches
+	MaxTokenPostings = 500000
+	// maxProbeLists is the most posting lists a query token may expand to for
+	// candidates to be looked up in them; tokens expanding to more, such as a
+	// lone 초성, are only checked on the address text
+	maxProbeLists = 16
+)
+
+// SearchTokens returns up to limit addresses in which every query token starts
+// some address token, in any order, so "역삼동 강남구" finds
+// "서울특별시 강남구 역삼동 ...". The token index yields the addresses
+// containing the most selective query token, which are intersected with the
+// postings of the other tokens; every survivor is checked on its text and
+// ranked before the limit is applied. Results keeping the query order and
+// adjacent tokens come first, then by score.
+func (nodes *NodeManager) SearchTokens(query string, limit int) []Result {
+	tokens := strings.Fields(query)
+	if limit <= 0 || len(tokens) < 2 {
+		return make([]Result, 0)
+	}
+
+	matcher := newTokenMatcher(tokens)
+
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	// 토큰마다 일치할 수 있는 게시 목록을 찾고 가장 적은 토큰을 기준으로 삼음
+	postings := make([]*tokenPostings, len(tokens))
+	driver := 0
+	for i, token := range tokens {
+		postings[i] = nodes.lookup(token)
+		if postings[i].count == 0 {
+			return make([]Result, 0)
+		}
+		if postings[i].count < postings[driver].count {
+			driver = i
+		}
+	}
+	if postings[driver].count > MaxTokenPostings {
+		return make([]Result, 0)
+	}
+	postings[driver].expand(-1)
+
+	probes := make([]*tokenPostings, 0, len(postings)-1)
+	for i, other := range postings {
+		if i != driver && other.expand(maxProbeLists) {
+			probes = append(probes, other)
+		}
+	}
+
+	// 모든 토큰의 게시 목록에 있는 주소를 순서, 간격, 점수 순으로 limit개만 남김
+	best := make(candidateQueue, 0, limit)
+	postings[driver].each(func(id uint32) {
+		word := nodes.words[id]
+		if word == nil {
+			return
+		}
+		for _, probe := range probes {
+			if !probe.contains(id) {
+				return
+			}
+		}
+
+		disorder, gaps, ok := matcher.match(word)
+		if !ok {
+			return
+		}
+		c := tokenCandidate{score: word.Score, disorder: disorder, gaps: gaps}
+		// 주소 문자열은 남길 가능성이 있는 후보만 만듦
+		if len(best) == limit && compareRank(c, best[0]) > 0 {
+			return
+		}
+		c.result = Result{Address: word.combineParentsInternal(""), Payload: word.Payload}
+		if len(best) < limit {
+			heap.Push(&best, c)
+		} else if compareCandidates(c, best[0]) < 0 {
+			best[0] = c
+			heap.Fix(&best, 0)
+		}
+	})
+
+	slices.SortFunc(best, compareCandidates)
+	results := make([]Result, len(best))
+	for i, c := range best {
+		results[i] = c.result
+	}
+	return results
+}
+
+// tokenCandidate is an address found by SearchTokens with its ranking keys
+type tokenCandidate struct {
+	result   Result
+	score    float32
+	disorder int
+	gaps     int
+}
+
+// compareCandidates orders addresses keeping the query order and adjacent
+// tokens first, then by score and lot number
+func compareCandidates(a, b tokenCandidate) int {
+	if c := compareRank(a, b); c != 0 {
+		return c
+	}
+	return compareNatural(a.result.Address, b.result.Address)
+}
+
+// compareRank compares candidates on everything but their address
+func compareRank(a, b tokenCandidate) int {
+	if a.disorder != b.disorder {
+		return a.disorder - b.disorder
+	}
+	if a.gaps != b.gaps {
+		return a.gaps - b.gaps
+	}
+	if a.score != b.score {
+		if a.score > b.score {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// candidateQueue keeps the worst ranked candidate on top so it can be
+// replaced by a better one
+type candidateQueue []tokenCandidate
+
+func (q candidateQueue) Len() int { return len(q) }
+
+func (q candidateQueue) Less(i, j int) bool { return compareCandidates(q[i], q[j]) > 0 }
+
+func (q candidateQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *candidateQueue) Push(x any) { *q = append(*q, x.(tokenCandidate)) }
+
+func (q *candidateQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// tokenMatcher checks addresses against the query tokens, reusing its
+// buffers from one address to the next
+type tokenMatcher struct {
+	tokens [][]rune
+	lots   []lotQuery
+	isLot  []bool
+
+	path  []rune
+	words [][]rune
+	used  []bool
+}
+
+func newTokenMatcher(tokens []string) *tokenMatcher {
+	matcher := &tokenMatcher{
+		tokens: make([][]rune, len(tokens)),
+		lots:   make([]lotQuery, len(tokens)),
+		isLot:  make([]bool, len(tokens)),
+	}
+	for i, token := range tokens {
+		matcher.tokens[i] = []rune(token)
+		matcher.lots[i], matcher.isLot[i] = parseLotQuery(token)
+	}
+	return matcher
+}
+
+// match splits the address ending at word into its tokens and matches the
+// query tokens against them with matchTokens
+func (matcher *tokenMatcher) match(word *FullNode) (disorder, gaps int, ok bool) {
+	// 부모를 따라 올라가며 주소를 거꾸로 모은 뒤 뒤집음
+	matcher.path = matcher.path[:0]
+	for node := word; node.Parent != nil; node = node.Parent {
+		matcher.path = append(matcher.path, node.Value)
+	}
+	slices.Reverse(matcher.path)
+
+	matcher.words = matcher.words[:0]
+	start := 0
+	for i, r := range matcher.path {
+		if r == ' ' {
+			if i > start {
+				matcher.words = append(matcher.words, matcher.path[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(matcher.path) {
+		matcher.words = append(matcher.words, matcher.path[start:])
+	}
+
+	matcher.used = slices.Grow(matcher.used[:0], len(matcher.words))[:len(matcher.words)]
+	clear(matcher.used)
+	return matcher.matchTokens()
+}
+
+// matchTokens assigns every query token to a distinct address token it is a
+// prefix of, preferring the earliest one. disorder counts consecutive query
+// tokens matched out of order and gaps the address tokens skipped between them.
+func (matcher *tokenMatcher) matchTokens() (disorder, gaps int, ok bool) {
+	words, used := matcher.words, matcher.used
+	previous := -1
+	for i, queryToken := range matcher.tokens {
+		position := -1
+		for j, word := range words {
+			if used[j] {
+				continue
+			}
+			if matcher.isLot[i] && lotTokenMatches(words, j, matcher.lots[i]) || !matcher.isLot[i] && tokenHasPrefix(word, queryToken) {
+				position = j
+				break
+			}
+		}
+		if position < 0 {
+			return 0, 0, false
+		}
+		used[position] = true
+
+		if i > 0 {
+			if position < previous {
+				disorder++
+				gaps += previous - position - 1
+			} else {
+				gaps += position - previous - 1
+			}
+		}
+		previous = position
+	}
+
+	return disorder, gaps, true
+}
+
+// lotTokenMatches reports whether the address word at j is the lot number of a
+// lot query token, looking at the preceding word for a separate 산
+func lotTokenMatches(words [][]rune, j int, lot lotQuery) bool {
+	word := string(words[j])
+	mountain := false
+	if rest, ok := strings.CutPrefix(word, string(mountainPrefix)); ok {
+		word, mountain = rest, true
+	} else if j > 0 && string(words[j-1]) == string(mountainPrefix) {
+		mountain = true
+	}
+
+	if lot.mountain && !mountain {
+		return false
+	}
+	if strings.Contains(lot.number, "-") {
+		return strings.HasPrefix(word, lot.number)
+	}
+	return word == lot.number || strings.HasPrefix(word, lot.number+"-")
+}
+
+// tokenHasPrefix reports whether word starts with prefix under the same rules
+// as the trie search: 초성 match whole syllables and the last rune may be an
+// unfinished syllable.
+func tokenHasPrefix(word, prefix []rune) bool {
+	if len(prefix) == 0 || len(prefix) > len(word) {
+		return false
+	}
+
+	last := len(prefix) - 1
+	for i := 0; i < last; i++ {
+		if !matchRune(prefix[i], word[i]) {
+			return false
+		}
+	}
+	if matchRune(prefix[last], word[last]) {
+		return true
+	}
+
+	// 음절의 첫 자모는 초성이므로, 초성이 다르면 자모로 나누지 않고 건너뜀
+	if (hangul.IsSyllable(prefix[last]) || hangul.IsSyllable(word[last])) && hangul.Choseong(prefix[last]) != hangul.Choseong(word[last]) {
+		return false
+	}
+
+	// 마지막 글자는 조합 중일 수 있으므로 자모 단위로 비교
+	return hasRunePrefix(hangul.DecomposeString(string(word[last:])), hangul.Decompose(prefix[last]))
+}
@@ -0,0 +1,91 @@
+package trie
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSearchTokens(t *testing.T) {
+	nodes := CreateNodes()
+	for _, address := range []string{
+		"서울특별시 강남구 역삼동 737",
+		"서울특별시 강남구 역삼동 12",
+		"서울특별시 강남구 역삼동 12-3",
+		"서울특별시 강남구 역삼동 산 12",
+		"서울특별시 강남구 역삼동 120",
+		"서울특별시 강남구 삼성동 12",
+		"서울특별시 서초구 서초동 12",
+	} {
+		nodes.Insert(address, 0, nil)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// 순서가 맞는 주소가 먼저, 그 안에서는 번지 순서
+		{"강남구 12", []string{
+			"서울특별시 강남구 삼성동 12", "서울특별시 강남구 역삼동 12", "서울특별시 강남구 역삼동 12-3", "서울특별시 강남구 역삼동 산 12",
+		}},
+		{"역삼동 강남구", []string{
+			"서울특별시 강남구 역삼동 12", "서울특별시 강남구 역삼동 12-3", "서울특별시 강남구 역삼동 120",
+			"서울특별시 강남구 역삼동 737", "서울특별시 강남구 역삼동 산 12",
+		}},
+		{"역삼동 12-3", []string{"서울특별시 강남구 역삼동 12-3"}},
+		{"ㅇㅅㄷ ㄱㄴㄱ 737", []string{"서울특별시 강남구 역삼동 737"}},
+		{"서초 12", []string{"서울특별시 서초구 서초동 12"}},
+		{"역삼동 서초구", []string{}},
+		{"역삼동", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := addresses(nodes.SearchTokens(tt.query, 10)); !slices.Equal(got, tt.want) {
+			t.Errorf("SearchTokens(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTokensAfterDelete(t *testing.T) {
+	nodes := CreateNodes()
+	for i := range 100 {
+		nodes.Insert(fmt.Sprintf("서울특별시 강남구 역삼동 %d", i), 0, nil)
+	}
+
+	// 지운 주소가 게시 목록을 정리하기 전후 모두 나오지 않아야 함
+	for i := range 90 {
+		nodes.Delete(fmt.Sprintf("서울특별시 강남구 역삼동 %d", i))
+		if i == 10 || i == 89 {
+			if err := nodes.Validate(); err != nil {
+				t.Fatalf("Validate() after %d deletes error = %v", i+1, err)
+			}
+		}
+	}
+	nodes.Insert("서울특별시 강남구 역삼동 5", 0, nil)
+
+	want := []string{"서울특별시 강남구 역삼동 5"}
+	for i := 90; i < 100; i++ {
+		want = append(want, fmt.Sprintf("서울특별시 강남구 역삼동 %d", i))
+	}
+	if got := addresses(nodes.SearchTokens("역삼동 강남구", 20)); !slices.Equal(got, want) {
+		t.Errorf("SearchTokens() = %q, want %q", got, want)
+	}
+	if err := nodes.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+func TestSearchTokensRanksOrderBeforeScore(t *testing.T) {
+	nodes := CreateNodes()
+	// 순서가 뒤집힌 점수 높은 주소가 많아도 순서가 맞는 주소가 먼저
+	for i := range 5000 {
+		nodes.Insert(fmt.Sprintf("서초동 강남구 %d", i), 10, nil)
+	}
+	nodes.Insert("강남구 서초동 1", 0, nil)
+
+	got := addresses(nodes.SearchTokens("강남구 서초동", 3))
+	want := []string{"강남구 서초동 1", "서초동 강남구 0", "서초동 강남구 1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("SearchTokens() = %q, want %q", got, want)
+	}
+}
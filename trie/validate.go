@@ -0,0 +1,169 @@
+package trie
+
+import "fmt"
+
+// Stats summarises the size of a trie
+type Stats struct {
+	Nodes    int
+	Words    int
+	Payloads int
+	// Tokens is the number of distinct tokens in the token index
+	Tokens int
+	// Refs holds the number of jump references per SubNodes depth
+	Refs []int
+}
+
+// Stats counts the nodes, words and jump references of the trie
+func (nodes *NodeManager) Stats() Stats {
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	var stats Stats
+	nodes.MainNode.walk(func(node *FullNode) {
+		stats.Nodes++
+		if node.IsEnd {
+			stats.Words++
+		}
+		if node.Payload != nil {
+			stats.Payloads++
+		}
+	})
+
+	nodes.tokens.walk(func(token *tokenNode) {
+		if len(token.postings) > 0 {
+			stats.Tokens++
+		}
+	})
+
+	stats.Refs = make([]int, len(nodes.SubNodes))
+	for i, subNode := range nodes.SubNodes {
+		stats.Refs[i] = len(subNode.Ref)
+	}
+	return stats
+}
+
+// Validate checks the structural invariants search relies on: parent links,
+// sorted unique children, subtree scores, jump references that point into
+// MainNode right after a space and a token index listing every word under
+// each of its tokens.
+func (nodes *NodeManager) Validate() error {
+	nodes.mu.RLock()
+	defer nodes.mu.RUnlock()
+
+	if err := nodes.MainNode.validate(); err != nil {
+		return err
+	}
+
+	for depth, subNode := range nodes.SubNodes {
+		for _, ref := range subNode.Ref {
+			if ref == nil || ref.Parent == nil {
+				return fmt.Errorf("SubNodes[%d] holds a reference outside the trie", depth)
+			}
+
+			root := ref
+			for root.Parent != nil {
+				root = root.Parent
+			}
+			if root != &nodes.MainNode {
+				return fmt.Errorf("SubNodes[%d] reference %q is not part of MainNode", depth, ref.combineParentsInternal(""))
+			}
+
+			if ref.Parent.child(ref.Value) != ref {
+				return fmt.Errorf("SubNodes[%d] reference %q was removed from the trie", depth, ref.combineParentsInternal(""))
+			}
+
+			if ref.Parent.Value != ' ' {
+				return fmt.Errorf("SubNodes[%d] reference %q does not start a word", depth, ref.combineParentsInternal(""))
+			}
+		}
+	}
+
+	return nodes.validateTokens()
+}
+
+// validateTokens checks that the postings are ordered ids of words, that the
+// deleted ones are counted as stale and that the rest add up to the tokens of
+// every word in the trie
+func (nodes *NodeManager) validateTokens() error {
+	expected := 0
+	var err error
+	nodes.MainNode.walk(func(node *FullNode) {
+		if !node.IsEnd {
+			return
+		}
+		if err == nil && (int(node.id) >= len(nodes.words) || nodes.words[node.id] != node) {
+			err = fmt.Errorf("word %q has no id in the token index", node.combineParentsInternal(""))
+		}
+		expected += len(addressTokens(node.combineParentsInternal("")))
+	})
+	if err != nil {
+		return err
+	}
+
+	actual := 0
+	nodes.tokens.walk(func(token *tokenNode) {
+		count := len(token.postings)
+		for _, child := range token.children {
+			count += child.count
+		}
+		if err == nil && count != token.count {
+			err = fmt.Errorf("token index node %q counts %d postings instead of %d", string(token.value), token.count, count)
+		}
+
+		stale := 0
+		for i, id := range token.postings {
+			if err == nil && (id == 0 || int(id) >= len(nodes.words)) {
+				err = fmt.Errorf("token index lists unknown id %d", id)
+				return
+			}
+			if err == nil && i > 0 && token.postings[i-1] >= id {
+				err = fmt.Errorf("token index postings of node %q are not ordered by id", string(token.value))
+			}
+			if word := nodes.words[id]; word == nil {
+				stale++
+			} else if err == nil && !word.IsEnd {
+				err = fmt.Errorf("token index lists %q, which ends no word", word.combineParentsInternal(""))
+			}
+		}
+		if err == nil && stale != token.stale {
+			err = fmt.Errorf("token index node %q counts %d deleted postings instead of %d", string(token.value), token.stale, stale)
+		}
+		actual += len(token.postings) - stale
+	})
+	if err != nil {
+		return err
+	}
+
+	if actual != expected {
+		return fmt.Errorf("token index holds %d postings but the words have %d tokens", actual, expected)
+	}
+	return nil
+}
+
+func (node *FullNode) validate() error {
+	best := float32(0)
+	if node.IsEnd {
+		best = node.Score
+	}
+	if node.Parent != nil && !node.IsEnd && len(node.Children) == 0 {
+		return fmt.Errorf("node %q ends no word", node.combineParentsInternal(""))
+	}
+
+	for i, child := range node.Children {
+		if child.Parent != node {
+			return fmt.Errorf("node %q has a wrong parent link", child.combineParentsInternal(""))
+		}
+		if i > 0 && node.Children[i-1].Value >= child.Value {
+			return fmt.Errorf("children of %q are not sorted", node.combineParentsInternal(""))
+		}
+		if err := child.validate(); err != nil {
+			return err
+		}
+		best = max(best, child.MaxScore)
+	}
+
+	if node.MaxScore < best {
+		return fmt.Errorf("node %q has MaxScore %v below its subtree best %v", node.combineParentsInternal(""), node.MaxScore, best)
+	}
+	return nil
+}